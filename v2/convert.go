@@ -0,0 +1,47 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"encoding/json"
+	"errors"
+	"syscall/js"
+)
+
+// goToJS converts v to a JavaScript value by encoding it as JSON.
+// js.Values are returned unchanged.
+func goToJS(v interface{}) (js.Value, error) {
+	if o, ok := v.(js.Value); ok {
+		return o, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return js.Undefined(), err
+	}
+	return js.Global().Get("JSON").Call("parse", string(b)), nil
+}
+
+// jsToGo decodes the JavaScript value o into dst, which must be a
+// pointer, by encoding it as JSON. If dst is a *js.Value, o is stored
+// unchanged, and if dst is a *[]byte and o a Uint8Array, the bytes are
+// copied.
+func jsToGo(o js.Value, dst interface{}) (err error) {
+	switch p := dst.(type) {
+	case *js.Value:
+		*p = o
+		return nil
+	case *[]byte:
+		if u8 := js.Global().Get("Uint8Array"); o.Type() == js.TypeObject && o.InstanceOf(u8) {
+			*p = make([]byte, o.Length())
+			js.CopyBytesToGo(*p, o)
+			return nil
+		}
+	}
+	defer recoverError(&err)
+	s := js.Global().Get("JSON").Call("stringify", o)
+	if s.IsUndefined() {
+		return errors.New("dom: value cannot be represented as JSON")
+	}
+	return json.Unmarshal([]byte(s.String()), dst)
+}
@@ -0,0 +1,160 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"syscall/js"
+)
+
+// CustomElementRegistry allows defining custom elements, also known
+// as web components, whose behaviour is implemented in Go.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/CustomElementRegistry.
+type CustomElementRegistry struct {
+	js.Value
+	global js.Value // the window the registry belongs to, or undefined for the current one
+}
+
+// ElementDefinition describes a custom element. All callbacks are
+// optional.
+type ElementDefinition struct {
+	// ConnectedCallback is called each time the element is added to a
+	// document.
+	ConnectedCallback func(el HTMLElement)
+	// DisconnectedCallback is called each time the element is removed
+	// from a document.
+	DisconnectedCallback func(el HTMLElement)
+	// AttributeChangedCallback is called when one of the attributes
+	// listed in ObservedAttributes is added, removed or changed.
+	// Missing values are represented by the empty string.
+	AttributeChangedCallback func(el HTMLElement, name, oldValue, newValue string)
+	// AdoptedCallback is called each time the element is moved to a
+	// new document.
+	AdoptedCallback func(el HTMLElement, oldDocument, newDocument Document)
+	// ObservedAttributes lists the attributes whose changes trigger
+	// AttributeChangedCallback.
+	ObservedAttributes []string
+	// Extends is the name of the built-in element that is extended by
+	// a customized built-in element, such as "button". Autonomous
+	// custom elements, which extend HTMLElement, leave it empty.
+	Extends string
+	// Wrap, if not nil, is registered with RegisterElementWrapper for
+	// the new element's constructor, so that instances of the custom
+	// element are returned as application-defined Go types. The
+	// callbacks receive the result of Wrap, too.
+	Wrap func(js.Value) HTMLElement
+}
+
+// Define defines a new custom element called name. It returns an
+// error if name isn't a valid custom element name or if name or the
+// constructor have already been defined.
+//
+// Custom elements cannot be undefined, so the functions in def are
+// never released.
+func (r *CustomElementRegistry) Define(name string, def ElementDefinition) error {
+	global := js.Global()
+	// The base classes have to come from the registry's own realm, such
+	// as that of an iframe.
+	realm := r.global
+	if realm.IsUndefined() {
+		realm = global
+	}
+	base := realm.Get("HTMLElement")
+	var opts interface{}
+	if def.Extends != "" {
+		base = realm.Get("document").Call("createElement", def.Extends).Get("constructor")
+		opts = map[string]interface{}{"extends": def.Extends}
+	}
+
+	// funcs collects the functions to release if the definition fails.
+	var funcs []js.Func
+	callback := func(fn func(this js.Value, args []js.Value)) js.Func {
+		f := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			fn(this, args)
+			return nil
+		})
+		funcs = append(funcs, f)
+		return f
+	}
+
+	// Custom element constructors have to construct the base element
+	// with new.target set to the custom constructor, which a Go
+	// function cannot observe. Reflect.construct achieves the same for
+	// ctor itself, which is all the custom element registry needs.
+	var ctor js.Func
+	ctor = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		return global.Get("Reflect").Call("construct", base, []interface{}{}, ctor.Value)
+	})
+	funcs = append(funcs, ctor)
+	object := global.Get("Object")
+	object.Call("setPrototypeOf", ctor.Value, base)
+	proto := object.Call("create", base.Get("prototype"))
+	proto.Set("constructor", ctor.Value)
+	ctor.Set("prototype", proto)
+
+	if def.ConnectedCallback != nil {
+		proto.Set("connectedCallback", callback(func(this js.Value, _ []js.Value) {
+			def.ConnectedCallback(wrapHTMLElement(this))
+		}))
+	}
+	if def.DisconnectedCallback != nil {
+		proto.Set("disconnectedCallback", callback(func(this js.Value, _ []js.Value) {
+			def.DisconnectedCallback(wrapHTMLElement(this))
+		}))
+	}
+	if def.AttributeChangedCallback != nil {
+		proto.Set("attributeChangedCallback", callback(func(this js.Value, args []js.Value) {
+			def.AttributeChangedCallback(wrapHTMLElement(this), args[0].String(), toString(args[1]), toString(args[2]))
+		}))
+	}
+	if def.AdoptedCallback != nil {
+		proto.Set("adoptedCallback", callback(func(this js.Value, args []js.Value) {
+			def.AdoptedCallback(wrapHTMLElement(this), wrapDocument(args[0]), wrapDocument(args[1]))
+		}))
+	}
+	observed := make([]interface{}, len(def.ObservedAttributes))
+	for i, attr := range def.ObservedAttributes {
+		observed[i] = attr
+	}
+	ctor.Set("observedAttributes", observed)
+
+	// Register the wrapper first, as defining the element immediately
+	// upgrades existing instances.
+	if def.Wrap != nil {
+		RegisterElementWrapper(ctor.Value, def.Wrap)
+	}
+	if err := callRecover(r.Value, "define", name, ctor.Value, opts); err != nil {
+		if def.Wrap != nil {
+			unregisterElementWrapper(ctor.Value)
+		}
+		for _, f := range funcs {
+			f.Release()
+		}
+		return err
+	}
+	return nil
+}
+
+// Get returns the constructor of the custom element called name, or
+// undefined if no such element has been defined.
+func (r *CustomElementRegistry) Get(name string) js.Value {
+	return r.Call("get", name)
+}
+
+// WhenDefined blocks until the custom element called name has been
+// defined and returns its constructor. It returns an error if name
+// isn't a valid custom element name.
+//
+// Like all blocking functions, it must not be called directly from a
+// JavaScript callback such as an event listener.
+func (r *CustomElementRegistry) WhenDefined(name string) (js.Value, error) {
+	return await(r.Call("whenDefined", name))
+}
+
+// Upgrade upgrades all custom elements in root's subtree, including
+// root itself, that haven't been upgraded yet because they were
+// created before their definition or aren't connected to a document.
+func (r *CustomElementRegistry) Upgrade(root Node) {
+	r.Call("upgrade", root.Underlying())
+}
@@ -31,6 +31,12 @@
 // GetWindow() function which will return a Window, from which you can
 // get things such as the current Document.
 //
+// Programs that run in a web worker have no window, and GetWindow
+// panics there. GetGlobalScope returns the parts of the global object
+// that windows and workers share, such as timers and Fetch, and
+// GetDedicatedWorkerGlobalScope the global object of a dedicated
+// worker.
+//
 // # Interfaces
 //
 // The DOM has a big amount of different element and event types, but
@@ -60,6 +66,13 @@
 // magically changing data isn't Go's nature and that snapshots of
 // state are a lot easier to reason about.
 //
+// For large collections, creating a snapshot on every call can be
+// costly. Methods with a Live suffix, such as ChildNodesLive and
+// GetElementsByTagNameLive, return the underlying live collection
+// instead, wrapped in a NodeList or HTMLCollection, which only wrap
+// individual items when accessed. With Go 1.23 and later, these types
+// can also be iterated over with range, via their All methods.
+//
 // This does not, however, mean that all objects are snapshots.
 // Elements, events and generally objects that aren't slices or maps
 // are simple wrappers around JavaScript objects, and as such
@@ -90,6 +103,22 @@
 // which will be able to accomplish the same. Additionally, our
 // TokenList will provide methods to convert it to strings and slices.
 //
+// # Errors
+//
+// Many DOM methods throw exceptions, for example when inserting a
+// node where it isn't allowed or when passing an invalid CSS
+// selector. By default, these bindings turn exceptions into panics,
+// like the JavaScript APIs they wrap. For methods that are likely to
+// throw, there are variants with an Err suffix, such as
+// AppendChildErr or QuerySelectorErr, that return the exception as an
+// error instead. Exceptions of type DOMException are returned as
+// *DOMException and can be inspected with errors.Is:
+//
+//	el, err := doc.QuerySelectorErr(userInput)
+//	if errors.Is(err, dom.ErrSyntax) {
+//		// invalid selector
+//	}
+//
 // # Backwards compatibility
 //
 // This package has a relatively stable API. However, there will be
@@ -109,9 +138,14 @@
 package dom // import "honnef.co/go/js/dom/v2"
 
 import (
+	"context"
+	"fmt"
 	"image"
 	"image/color"
+	"math"
+	"net/url"
 	"strings"
+	"sync"
 	"syscall/js"
 	"time"
 )
@@ -125,20 +159,170 @@ func toString(o js.Value) string {
 	return o.String()
 }
 
-func callRecover(o js.Value, fn string, args ...interface{}) (err error) {
-	defer func() {
-		e := recover()
-		if e == nil {
-			return
+// constructorTable maps JavaScript constructors to indices into a
+// list of wrapper functions. It replaces comparing a constructor
+// against every known global one by one, which requires two calls
+// into JavaScript per comparison, with a single lookup in a
+// JavaScript Map.
+//
+// Constructors that aren't in the table themselves, such as those of
+// subclasses and custom elements, resolve to the nearest constructor
+// in their prototype chain that is.
+type constructorTable struct {
+	m      js.Value // constructors that have been added to the table
+	cache  js.Value // resolved indices of constructors seen by lookup, in a WeakMap
+	object js.Value // the realm's Object, for Object.getPrototypeOf
+}
+
+// newConstructorTable creates a table that maps the constructors
+// found under names in global to their index in names. Constructors
+// that don't exist in global are skipped. If several names refer to
+// the same constructor, the first one wins.
+func newConstructorTable(global js.Value, names []string) *constructorTable {
+	t := &constructorTable{
+		m:      global.Get("Map").New(),
+		cache:  global.Get("WeakMap").New(),
+		object: global.Get("Object"),
+	}
+	for i, name := range names {
+		c := global.Get(name)
+		if c.IsUndefined() || t.m.Call("has", c).Bool() {
+			continue
 		}
-		if panicErr, ok := e.(error); ok && panicErr != nil {
-			err = panicErr
-		} else {
-			panic(e)
+		t.m.Call("set", c, i)
+	}
+	return t
+}
+
+// add maps the constructor c to index i, replacing any existing
+// mapping for c.
+func (t *constructorTable) add(c js.Value, i int) {
+	t.m.Call("set", c, i)
+	// Previously resolved subclasses may now resolve to c.
+	t.clearCache()
+}
+
+// remove removes the mapping for the constructor c.
+func (t *constructorTable) remove(c js.Value) {
+	t.m.Call("delete", c)
+	t.clearCache()
+}
+
+// clearCache forgets all resolved constructors. The cache is a
+// WeakMap, so that constructors that are no longer used, such as those
+// of custom elements defined by Go code, can be collected, and WeakMaps
+// can't be cleared.
+func (t *constructorTable) clearCache() {
+	t.cache = t.cache.Get("constructor").New()
+}
+
+// lookup returns the index of the constructor c or of its nearest
+// ancestor in the table, or -1 if there is none.
+func (t *constructorTable) lookup(c js.Value) int {
+	if i := t.cache.Call("get", c); !i.IsUndefined() {
+		return i.Int()
+	}
+	i := -1
+	// The prototype of a class is the class it extends. The chain ends
+	// with Function.prototype, whose prototype isn't a function.
+	for p := c; p.Type() == js.TypeFunction; p = t.object.Call("getPrototypeOf", p) {
+		if v := t.m.Call("get", p); !v.IsUndefined() {
+			i = v.Int()
+			break
 		}
-	}()
-	o.Call(fn, args...)
-	return nil
+	}
+	if c.Type() == js.TypeFunction {
+		t.cache.Call("set", c, i)
+	}
+	return i
+}
+
+// realmTables holds the constructor tables of realms other than the
+// one the program runs in, such as those of same-origin iframes. The
+// tables are stored in a WeakMap keyed by the realm's window, so that
+// they are collected along with it.
+type realmTables struct {
+	once  sync.Once
+	m     js.Value
+	names func() []string
+}
+
+// get returns the table for the realm whose global object is realm,
+// creating it if necessary.
+func (r *realmTables) get(realm js.Value) *constructorTable {
+	r.once.Do(func() { r.m = js.Global().Get("WeakMap").New() })
+	if v := r.m.Call("get", realm); !v.IsUndefined() {
+		return &constructorTable{m: v.Index(0), cache: v.Index(1), object: v.Index(2)}
+	}
+	t := newConstructorTable(realm, r.names())
+	r.m.Call("set", realm, []interface{}{t.m, t.cache, t.object})
+	return t
+}
+
+// foreignRealm returns the window of the realm that the node o belongs
+// to, or undefined if that is the realm the program runs in or if o
+// doesn't belong to a window, as is the case for documents created by
+// DOMParser.
+func foreignRealm(o js.Value) js.Value {
+	doc := o.Get("ownerDocument")
+	if doc.IsNull() {
+		// Documents are their own owner document.
+		doc = o
+	}
+	// Most nodes belong to the main document, which can be checked for
+	// without further calls into JavaScript.
+	if doc.IsUndefined() || isMainDocument(doc) {
+		return js.Undefined()
+	}
+	w := doc.Get("defaultView")
+	if w.IsNull() || w.IsUndefined() || w.Equal(js.Global()) {
+		return js.Undefined()
+	}
+	return w
+}
+
+// mainDocument caches the document of the realm that the program runs
+// in. It stays undefined, and is looked up again, until there is one.
+var mainDocument js.Value
+
+func isMainDocument(doc js.Value) bool {
+	if mainDocument.IsUndefined() {
+		mainDocument = js.Global().Get("document")
+	}
+	return doc.Equal(mainDocument)
+}
+
+// realmGlobal returns the global object of the realm that the node o
+// belongs to.
+func realmGlobal(o js.Value) js.Value {
+	if w := foreignRealm(o); !w.IsUndefined() {
+		return w
+	}
+	return js.Global()
+}
+
+// await blocks until the promise p settles. It returns the value p
+// was fulfilled with, or the reason it was rejected with as an error.
+func await(p js.Value) (js.Value, error) {
+	type result struct {
+		v   js.Value
+		err error
+	}
+	ch := make(chan result, 1)
+	var onFulfilled, onRejected js.Func
+	onFulfilled = js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		ch <- result{v: args[0]}
+		return nil
+	})
+	onRejected = js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		ch <- result{err: wrapError(args[0])}
+		return nil
+	})
+	p.Call("then", onFulfilled, onRejected)
+	r := <-ch
+	onFulfilled.Release()
+	onRejected.Release()
+	return r.v, r.err
 }
 
 func elementConstructor(o js.Value) js.Value {
@@ -171,6 +355,49 @@ func nodeListToObjects(o js.Value) []js.Value {
 	return out
 }
 
+// NodeList wraps a JavaScript NodeList, which may be live or static.
+// Items are only wrapped when accessed. Live lists reflect changes to
+// the DOM as they happen; their length and items may change between
+// calls.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/NodeList.
+type NodeList struct {
+	js.Value
+}
+
+// Len returns the number of nodes in the list.
+func (l *NodeList) Len() int { return l.Length() }
+
+// Item returns the node at index i, or nil if i is out of range.
+func (l *NodeList) Item(i int) Node { return wrapNode(l.Index(i)) }
+
+// Slice returns a snapshot of the list's current nodes.
+func (l *NodeList) Slice() []Node { return nodeListToNodes(l.Value) }
+
+// HTMLCollection wraps a JavaScript HTMLCollection, a live list of
+// elements. Items are only wrapped when accessed, and the length and
+// items of the collection may change between calls.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/HTMLCollection.
+type HTMLCollection struct {
+	js.Value
+}
+
+// Len returns the number of elements in the collection.
+func (c *HTMLCollection) Len() int { return c.Length() }
+
+// Item returns the element at index i, or nil if i is out of range.
+func (c *HTMLCollection) Item(i int) Element { return wrapElement(c.Index(i)) }
+
+// NamedItem returns the first element whose id or name attribute is
+// name, or nil if there is none.
+func (c *HTMLCollection) NamedItem(name string) Element {
+	return wrapElement(c.Call("namedItem", name))
+}
+
+// Slice returns a snapshot of the collection's current elements.
+func (c *HTMLCollection) Slice() []Element { return nodeListToElements(c.Value) }
+
 func nodeListToNodes(o js.Value) []Node {
 	var out []Node
 	for _, obj := range nodeListToObjects(o) {
@@ -216,17 +443,64 @@ func WrapHTMLElement(o js.Value) HTMLElement {
 }
 
 func wrapDocument(o js.Value) Document {
-	switch c := elementConstructor(o); {
-	case c.Equal(js.Global().Get("HTMLDocument")):
-		return &htmlDocument{&document{&BasicNode{o}}}
-	default:
-		return &document{&BasicNode{o}}
+	if o.IsNull() || o.IsUndefined() {
+		return nil
+	}
+	t := documentConstructors()
+	if realm := foreignRealm(o); !realm.IsUndefined() {
+		t = foreignDocumentConstructors.get(realm)
+	}
+	d := &document{&BasicNode{o}}
+	if i := t.lookup(elementConstructor(o)); i >= 0 {
+		return documentWrappers[i].wrap(d)
+	}
+	return d
+}
+
+// documentWrappers maps the names of document constructors to
+// functions that wrap a document in the corresponding concrete type.
+// The HTML standard defines the API of HTMLDocument on Document, so
+// plain Documents, such as those created by DOMParser, are wrapped as
+// HTMLDocuments, too. XMLDocument, which extends Document, comes first
+// so that it isn't resolved to Document.
+var documentWrappers = []struct {
+	name string
+	wrap func(d *document) Document
+}{
+	{"XMLDocument", func(d *document) Document { return d }},
+	{"HTMLDocument", func(d *document) Document { return &htmlDocument{d} }},
+	{"Document", func(d *document) Document { return &htmlDocument{d} }},
+}
+
+var (
+	documentConstructorsOnce sync.Once
+	documentConstructorTable *constructorTable
+)
+
+func documentConstructors() *constructorTable {
+	documentConstructorsOnce.Do(func() {
+		documentConstructorTable = newConstructorTable(js.Global(), documentWrapperNames())
+	})
+	return documentConstructorTable
+}
+
+var foreignDocumentConstructors = &realmTables{names: documentWrapperNames}
+
+func documentWrapperNames() []string {
+	names := make([]string, len(documentWrappers))
+	for i, w := range documentWrappers {
+		names[i] = w.name
 	}
+	return names
 }
 
 func wrapDocumentFragment(o js.Value) DocumentFragment {
-	switch /*c := elementConstructor(o);*/ {
-	// TODO: do we have any other stuff we want to check
+	if o.IsNull() || o.IsUndefined() {
+		return nil
+	}
+	switch c := elementConstructor(o); {
+	case c.Equal(realmGlobal(o).Get("ShadowRoot")):
+		return &shadowRoot{&documentFragment{&BasicNode{o}}}
 	default:
 		return &documentFragment{&BasicNode{o}}
 	}
@@ -237,10 +511,32 @@ func wrapNode(o js.Value) Node {
 		return nil
 	}
 	switch c := elementConstructor(o); {
-	// TODO all the non-element cases
+	// TODO all the remaining non-element cases
 	case c.Equal(js.Global().Get("Text")):
 		return &Text{&BasicNode{o}}
 	default:
+		// Polymer's wrappers don't have a nodeType.
+		if nt := o.Get("nodeType"); nt.Type() == js.TypeNumber {
+			switch nt.Int() {
+			case 2:
+				return &Attr{&BasicNode{o}}
+			case 3:
+				// Text nodes from other realms, such as iframes.
+				return &Text{&BasicNode{o}}
+			case 4:
+				return &CDATASection{&Text{&BasicNode{o}}}
+			case 7:
+				return &ProcessingInstruction{&BasicNode{o}}
+			case 8:
+				return &Comment{&BasicNode{o}}
+			case 9:
+				return wrapDocument(o)
+			case 10:
+				return &documentType{&BasicNode{o}}
+			case 11:
+				return wrapDocumentFragment(o)
+			}
+		}
 		return wrapElement(o)
 	}
 }
@@ -260,154 +556,197 @@ func wrapHTMLElement(o js.Value) HTMLElement {
 	if o.IsNull() || o.IsUndefined() {
 		return nil
 	}
-	el := &BasicHTMLElement{&BasicElement{&BasicNode{o}}}
 	c := elementConstructor(o)
-	switch {
-	case c.Equal(js.Global().Get("HTMLAnchorElement")):
-		return &HTMLAnchorElement{BasicHTMLElement: el, URLUtils: &URLUtils{Value: o}}
-	case c.Equal(js.Global().Get("HTMLAppletElement")):
-		return &HTMLAppletElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLAreaElement")):
-		return &HTMLAreaElement{BasicHTMLElement: el, URLUtils: &URLUtils{Value: o}}
-	case c.Equal(js.Global().Get("HTMLAudioElement")):
+	i := htmlElementConstructors().lookup(c)
+	if i < 0 {
+		// Elements from other realms, such as iframes, have their
+		// realm's constructors. Registered wrappers take precedence, as
+		// they are kept in the main table regardless of realm.
+		if realm := foreignRealm(o); !realm.IsUndefined() {
+			i = foreignHTMLElementConstructors.get(realm).lookup(c)
+		}
+	}
+	if i >= len(htmlElementWrappers) {
+		return registeredElementWrappers[i-len(htmlElementWrappers)](o)
+	}
+	el := &BasicHTMLElement{&BasicElement{&BasicNode{o}}}
+	if i >= 0 {
+		return htmlElementWrappers[i].wrap(el)
+	}
+	return el
+}
+
+// registeredElementWrappers holds the wrappers registered with
+// RegisterElementWrapper. In the constructor table, they follow the
+// entries of htmlElementWrappers.
+var registeredElementWrappers []func(js.Value) HTMLElement
+
+// RegisterElementWrapper registers wrap as the function that wraps
+// elements constructed by ctor, or by any subclass of ctor that has
+// no more specific wrapper. This allows custom elements to be
+// returned as application-defined Go types from all functions in
+// this package that return elements. Registering a wrapper for a
+// constructor that already has one replaces it, including the
+// built-in ones.
+//
+// wrap must not call WrapHTMLElement or similar functions on its
+// argument, as that would call wrap again. Instead, it should
+// construct the BasicHTMLElement directly:
+//
+//	dom.RegisterElementWrapper(ctor, func(o js.Value) dom.HTMLElement {
+//		return &MyWidget{&dom.BasicHTMLElement{&dom.BasicElement{&dom.BasicNode{o}}}}
+//	})
+func RegisterElementWrapper(ctor js.Value, wrap func(js.Value) HTMLElement) {
+	t := htmlElementConstructors()
+	// Reuse the slot of an earlier registration for ctor, or one that
+	// has been freed, so that registering the same constructor again
+	// doesn't grow the list.
+	slot := registeredElementWrapperSlot(t, ctor)
+	if slot < 0 {
+		for i, w := range registeredElementWrappers {
+			if w == nil {
+				slot = i
+				break
+			}
+		}
+	}
+	if slot < 0 {
+		registeredElementWrappers = append(registeredElementWrappers, wrap)
+		slot = len(registeredElementWrappers) - 1
+	} else {
+		registeredElementWrappers[slot] = wrap
+	}
+	t.add(ctor, len(htmlElementWrappers)+slot)
+}
+
+// unregisterElementWrapper removes the wrapper registered for ctor and
+// frees its slot in registeredElementWrappers.
+func unregisterElementWrapper(ctor js.Value) {
+	t := htmlElementConstructors()
+	if slot := registeredElementWrapperSlot(t, ctor); slot >= 0 {
+		registeredElementWrappers[slot] = nil
+		t.remove(ctor)
+	}
+}
+
+// registeredElementWrapperSlot returns the index into
+// registeredElementWrappers of the wrapper registered for ctor itself,
+// or -1 if there is none.
+func registeredElementWrapperSlot(t *constructorTable, ctor js.Value) int {
+	if i := t.m.Call("get", ctor); !i.IsUndefined() && i.Int() >= len(htmlElementWrappers) {
+		return i.Int() - len(htmlElementWrappers)
+	}
+	return -1
+}
+
+// htmlElementWrappers maps the names of HTML element constructors to
+// functions that wrap a BasicHTMLElement in the corresponding
+// concrete type.
+var htmlElementWrappers = []struct {
+	name string
+	wrap func(el *BasicHTMLElement) HTMLElement
+}{
+	{"HTMLAnchorElement", func(el *BasicHTMLElement) HTMLElement {
+		return &HTMLAnchorElement{BasicHTMLElement: el, URLUtils: &URLUtils{Value: el.Value}}
+	}},
+	{"HTMLAppletElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLAppletElement{BasicHTMLElement: el} }},
+	{"HTMLAreaElement", func(el *BasicHTMLElement) HTMLElement {
+		return &HTMLAreaElement{BasicHTMLElement: el, URLUtils: &URLUtils{Value: el.Value}}
+	}},
+	{"HTMLAudioElement", func(el *BasicHTMLElement) HTMLElement {
 		return &HTMLAudioElement{HTMLMediaElement: &HTMLMediaElement{BasicHTMLElement: el}}
-	case c.Equal(js.Global().Get("HTMLBaseElement")):
-		return &HTMLBaseElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLBodyElement")):
-		return &HTMLBodyElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLBRElement")):
-		return &HTMLBRElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLButtonElement")):
-		return &HTMLButtonElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLCanvasElement")):
-		return &HTMLCanvasElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLDataElement")):
-		return &HTMLDataElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLDataListElement")):
-		return &HTMLDataListElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLDetailsElement")):
-		return &HTMLDetailsElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLDirectoryElement")):
-		return &HTMLDirectoryElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLDivElement")):
-		return &HTMLDivElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLDListElement")):
-		return &HTMLDListElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLEmbedElement")):
-		return &HTMLEmbedElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLFieldSetElement")):
-		return &HTMLFieldSetElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLFontElement")):
-		return &HTMLFontElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLFormElement")):
-		return &HTMLFormElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLFrameElement")):
-		return &HTMLFrameElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLFrameSetElement")):
-		return &HTMLFrameSetElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLHeadElement")):
-		return &HTMLHeadElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLHeadingElement")):
-		return &HTMLHeadingElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLHtmlElement")):
-		return &HTMLHtmlElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLHRElement")):
-		return &HTMLHRElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLIFrameElement")):
-		return &HTMLIFrameElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLImageElement")):
-		return &HTMLImageElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLInputElement")):
-		return &HTMLInputElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLKeygenElement")):
-		return &HTMLKeygenElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLLabelElement")):
-		return &HTMLLabelElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLLegendElement")):
-		return &HTMLLegendElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLLIElement")):
-		return &HTMLLIElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLLinkElement")):
-		return &HTMLLinkElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLMapElement")):
-		return &HTMLMapElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLMediaElement")):
-		return &HTMLMediaElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLMenuElement")):
-		return &HTMLMenuElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLMetaElement")):
-		return &HTMLMetaElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLMeterElement")):
-		return &HTMLMeterElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLModElement")):
-		return &HTMLModElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLObjectElement")):
-		return &HTMLObjectElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLOListElement")):
-		return &HTMLOListElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLOptGroupElement")):
-		return &HTMLOptGroupElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLOptionElement")):
-		return &HTMLOptionElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLOutputElement")):
-		return &HTMLOutputElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLParagraphElement")):
-		return &HTMLParagraphElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLParamElement")):
-		return &HTMLParamElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLPreElement")):
-		return &HTMLPreElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLProgressElement")):
-		return &HTMLProgressElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLQuoteElement")):
-		return &HTMLQuoteElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLScriptElement")):
-		return &HTMLScriptElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLSelectElement")):
-		return &HTMLSelectElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLSourceElement")):
-		return &HTMLSourceElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLSpanElement")):
-		return &HTMLSpanElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLStyleElement")):
-		return &HTMLStyleElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLTableElement")):
-		return &HTMLTableElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLTableCaptionElement")):
-		return &HTMLTableCaptionElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLTableCellElement")):
-		return &HTMLTableCellElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLTableDataCellElement")):
-		return &HTMLTableDataCellElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLTableHeaderCellElement")):
-		return &HTMLTableHeaderCellElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLTableColElement")):
-		return &HTMLTableColElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLTableRowElement")):
-		return &HTMLTableRowElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLTableSectionElement")):
-		return &HTMLTableSectionElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLTemplateElement")):
-		return &HTMLTemplateElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLTextAreaElement")):
-		return &HTMLTextAreaElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLTimeElement")):
-		return &HTMLTimeElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLTitleElement")):
-		return &HTMLTitleElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLTrackElement")):
-		return &HTMLTrackElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLUListElement")):
-		return &HTMLUListElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLUnknownElement")):
-		return &HTMLUnknownElement{BasicHTMLElement: el}
-	case c.Equal(js.Global().Get("HTMLVideoElement")):
+	}},
+	{"HTMLBaseElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLBaseElement{BasicHTMLElement: el} }},
+	{"HTMLBodyElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLBodyElement{BasicHTMLElement: el} }},
+	{"HTMLBRElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLBRElement{BasicHTMLElement: el} }},
+	{"HTMLButtonElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLButtonElement{BasicHTMLElement: el} }},
+	{"HTMLCanvasElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLCanvasElement{BasicHTMLElement: el} }},
+	{"HTMLDataElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLDataElement{BasicHTMLElement: el} }},
+	{"HTMLDataListElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLDataListElement{BasicHTMLElement: el} }},
+	{"HTMLDetailsElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLDetailsElement{BasicHTMLElement: el} }},
+	{"HTMLDirectoryElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLDirectoryElement{BasicHTMLElement: el} }},
+	{"HTMLDivElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLDivElement{BasicHTMLElement: el} }},
+	{"HTMLDListElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLDListElement{BasicHTMLElement: el} }},
+	{"HTMLEmbedElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLEmbedElement{BasicHTMLElement: el} }},
+	{"HTMLFieldSetElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLFieldSetElement{BasicHTMLElement: el} }},
+	{"HTMLFontElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLFontElement{BasicHTMLElement: el} }},
+	{"HTMLFormElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLFormElement{BasicHTMLElement: el} }},
+	{"HTMLFrameElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLFrameElement{BasicHTMLElement: el} }},
+	{"HTMLFrameSetElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLFrameSetElement{BasicHTMLElement: el} }},
+	{"HTMLHeadElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLHeadElement{BasicHTMLElement: el} }},
+	{"HTMLHeadingElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLHeadingElement{BasicHTMLElement: el} }},
+	{"HTMLHtmlElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLHtmlElement{BasicHTMLElement: el} }},
+	{"HTMLHRElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLHRElement{BasicHTMLElement: el} }},
+	{"HTMLIFrameElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLIFrameElement{BasicHTMLElement: el} }},
+	{"HTMLImageElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLImageElement{BasicHTMLElement: el} }},
+	{"HTMLInputElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLInputElement{BasicHTMLElement: el} }},
+	{"HTMLKeygenElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLKeygenElement{BasicHTMLElement: el} }},
+	{"HTMLLabelElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLLabelElement{BasicHTMLElement: el} }},
+	{"HTMLLegendElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLLegendElement{BasicHTMLElement: el} }},
+	{"HTMLLIElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLLIElement{BasicHTMLElement: el} }},
+	{"HTMLLinkElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLLinkElement{BasicHTMLElement: el} }},
+	{"HTMLMapElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLMapElement{BasicHTMLElement: el} }},
+	{"HTMLMediaElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLMediaElement{BasicHTMLElement: el} }},
+	{"HTMLMenuElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLMenuElement{BasicHTMLElement: el} }},
+	{"HTMLMetaElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLMetaElement{BasicHTMLElement: el} }},
+	{"HTMLMeterElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLMeterElement{BasicHTMLElement: el} }},
+	{"HTMLModElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLModElement{BasicHTMLElement: el} }},
+	{"HTMLObjectElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLObjectElement{BasicHTMLElement: el} }},
+	{"HTMLOListElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLOListElement{BasicHTMLElement: el} }},
+	{"HTMLOptGroupElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLOptGroupElement{BasicHTMLElement: el} }},
+	{"HTMLOptionElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLOptionElement{BasicHTMLElement: el} }},
+	{"HTMLOutputElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLOutputElement{BasicHTMLElement: el} }},
+	{"HTMLParagraphElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLParagraphElement{BasicHTMLElement: el} }},
+	{"HTMLParamElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLParamElement{BasicHTMLElement: el} }},
+	{"HTMLPreElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLPreElement{BasicHTMLElement: el} }},
+	{"HTMLProgressElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLProgressElement{BasicHTMLElement: el} }},
+	{"HTMLQuoteElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLQuoteElement{BasicHTMLElement: el} }},
+	{"HTMLScriptElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLScriptElement{BasicHTMLElement: el} }},
+	{"HTMLSelectElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLSelectElement{BasicHTMLElement: el} }},
+	{"HTMLSlotElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLSlotElement{BasicHTMLElement: el} }},
+	{"HTMLSourceElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLSourceElement{BasicHTMLElement: el} }},
+	{"HTMLSpanElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLSpanElement{BasicHTMLElement: el} }},
+	{"HTMLStyleElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLStyleElement{BasicHTMLElement: el} }},
+	{"HTMLTableElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLTableElement{BasicHTMLElement: el} }},
+	{"HTMLTableCaptionElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLTableCaptionElement{BasicHTMLElement: el} }},
+	{"HTMLTableCellElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLTableCellElement{BasicHTMLElement: el} }},
+	{"HTMLTableDataCellElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLTableDataCellElement{BasicHTMLElement: el} }},
+	{"HTMLTableHeaderCellElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLTableHeaderCellElement{BasicHTMLElement: el} }},
+	{"HTMLTableColElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLTableColElement{BasicHTMLElement: el} }},
+	{"HTMLTableRowElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLTableRowElement{BasicHTMLElement: el} }},
+	{"HTMLTableSectionElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLTableSectionElement{BasicHTMLElement: el} }},
+	{"HTMLTemplateElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLTemplateElement{BasicHTMLElement: el} }},
+	{"HTMLTextAreaElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLTextAreaElement{BasicHTMLElement: el} }},
+	{"HTMLTimeElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLTimeElement{BasicHTMLElement: el} }},
+	{"HTMLTitleElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLTitleElement{BasicHTMLElement: el} }},
+	{"HTMLTrackElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLTrackElement{BasicHTMLElement: el} }},
+	{"HTMLUListElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLUListElement{BasicHTMLElement: el} }},
+	{"HTMLUnknownElement", func(el *BasicHTMLElement) HTMLElement { return &HTMLUnknownElement{BasicHTMLElement: el} }},
+	{"HTMLVideoElement", func(el *BasicHTMLElement) HTMLElement {
 		return &HTMLVideoElement{HTMLMediaElement: &HTMLMediaElement{BasicHTMLElement: el}}
-	case c.Equal(js.Global().Get("HTMLElement")):
-		return el
-	default:
-		return el
+	}},
+}
+
+var (
+	htmlElementConstructorsOnce sync.Once
+	htmlElementConstructorTable *constructorTable
+)
+
+func htmlElementConstructors() *constructorTable {
+	htmlElementConstructorsOnce.Do(func() {
+		htmlElementConstructorTable = newConstructorTable(js.Global(), htmlElementWrapperNames())
+	})
+	return htmlElementConstructorTable
+}
+
+var foreignHTMLElementConstructors = &realmTables{names: htmlElementWrapperNames}
+
+func htmlElementWrapperNames() []string {
+	names := make([]string, len(htmlElementWrappers))
+	for i, w := range htmlElementWrappers {
+		names[i] = w.name
 	}
+	return names
 }
 
 func getForm(o js.Value) *HTMLFormElement {
@@ -436,8 +775,23 @@ func getOptions(o js.Value, attr string) []*HTMLOptionElement {
 	return out
 }
 
+// GetWindow returns the global object as a Window. It panics with
+// ErrNotAWindow if the program isn't running in a window, such as in a
+// worker; use GetWindowErr to handle that case, or GetGlobalScope for
+// code that runs in both.
 func GetWindow() Window {
-	return &window{js.Global()}
+	w, err := GetWindowErr()
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+func wrapWindow(o js.Value) Window {
+	if o.IsNull() || o.IsUndefined() {
+		return nil
+	}
+	return &window{o}
 }
 
 type TokenList struct {
@@ -469,6 +823,21 @@ func (tl *TokenList) Toggle(token string) {
 	tl.dtl.Call("toggle", token)
 }
 
+// AddErr, RemoveErr and ToggleErr are like Add, Remove and Toggle,
+// but return an error instead of panicking if token is empty or
+// contains whitespace.
+func (tl *TokenList) AddErr(token string) error {
+	return callRecover(tl.dtl, "add", token)
+}
+
+func (tl *TokenList) RemoveErr(token string) error {
+	return callRecover(tl.dtl, "remove", token)
+}
+
+func (tl *TokenList) ToggleErr(token string) error {
+	return callRecover(tl.dtl, "toggle", token)
+}
+
 func (tl *TokenList) String() string {
 	if tl.sa != "" {
 		return tl.o.Get(tl.sa).String()
@@ -532,17 +901,36 @@ type Document interface {
 	ImportNode(node Node, deep bool) Node
 	CreateElement(name string) Element
 	CreateElementNS(namespace, name string) Element
+	CharacterSet() string
+	ContentType() string
+	CreateAttribute(name string) *Attr
+	CreateComment(data string) *Comment
 	CreateTextNode(s string) *Text
 	ElementFromPoint(x, y int) Element
+	// ElementsFromPoint returns all elements at the given coordinates,
+	// from the topmost to the bottommost.
+	ElementsFromPoint(x, y int) []Element
 	EnableStyleSheetsForSet(name string)
 	GetElementsByClassName(name string) []Element
 	GetElementsByTagName(name string) []Element
 	GetElementsByTagNameNS(ns, name string) []Element
+	GetElementsByClassNameLive(name string) *HTMLCollection
+	GetElementsByTagNameLive(name string) *HTMLCollection
+	GetElementsByTagNameNSLive(ns, name string) *HTMLCollection
 	GetElementByID(id string) Element
 	QuerySelector(sel string) Element
 	QuerySelectorAll(sel string) []Element
 
 	CreateDocumentFragment() DocumentFragment
+	CreateTreeWalker(root Node, whatToShow uint32, filter NodeFilter) *TreeWalker
+	CreateNodeIterator(root Node, whatToShow uint32, filter NodeFilter) *NodeIterator
+
+	AdoptNodeErr(node Node) (Node, error)
+	ImportNodeErr(node Node, deep bool) (Node, error)
+	CreateElementErr(name string) (Element, error)
+	CreateElementNSErr(namespace, name string) (Element, error)
+	QuerySelectorErr(sel string) (Element, error)
+	QuerySelectorAllErr(sel string) ([]Element, error)
 }
 
 type DocumentFragment interface {
@@ -551,6 +939,9 @@ type DocumentFragment interface {
 	QuerySelector(sel string) Element
 	QuerySelectorAll(sel string) []Element
 	GetElementByID(id string) Element
+
+	QuerySelectorErr(sel string) (Element, error)
+	QuerySelectorAllErr(sel string) ([]Element, error)
 }
 
 type HTMLDocument interface {
@@ -566,20 +957,52 @@ type HTMLDocument interface {
 	Domain() string
 	SetDomain(string)
 	Forms() []*HTMLFormElement
+	FormsLive() *HTMLCollection
 	Head() *HTMLHeadElement
 	Images() []*HTMLImageElement
+	ImagesLive() *HTMLCollection
 	LastModified() time.Time
 	Links() []HTMLElement
+	LinksLive() *HTMLCollection
 	Location() *Location
 	Plugins() []*HTMLEmbedElement
 	ReadyState() string
 	Referrer() string
 	Scripts() []*HTMLScriptElement
+	ScriptsLive() *HTMLCollection
 	Title() string
 	SetTitle(string)
 	URL() string
 
-	// TODO HTMLDocument methods
+	CurrentScript() Element
+	FullscreenElement() Element
+	FullscreenEnabled() bool
+	// Hidden reports whether the page is hidden, for example because it
+	// is in a background tab. Changes are signalled by the
+	// visibilitychange event.
+	Hidden() bool
+	PointerLockElement() Element
+	// VisibilityState is "visible" or "hidden". Changes are signalled by
+	// the visibilitychange event.
+	VisibilityState() string
+
+	// ExecCommand runs a legacy editing command on the current
+	// selection or in a contenteditable element. It reports whether the
+	// command is supported and enabled.
+	ExecCommand(command string, showUI bool, value string) bool
+	// ExitFullscreen takes the document out of fullscreen mode. It
+	// blocks until that has happened and must not be called directly
+	// from a JavaScript callback such as an event listener.
+	ExitFullscreen() error
+	ExitPointerLock()
+	HasFocus() bool
+	QueryCommandState(command string) bool
+	// StartViewTransition captures the current state of the page, calls
+	// update to change the DOM and animates the transition between the
+	// two states. update must not block. If the browser doesn't support
+	// view transitions, StartViewTransition calls update directly and
+	// returns a transition that has already finished.
+	StartViewTransition(update func()) *ViewTransition
 }
 
 type documentFragment struct {
@@ -598,6 +1021,167 @@ func (d documentFragment) QuerySelectorAll(sel string) []Element {
 	return (&BasicElement{&BasicNode{d.Value}}).QuerySelectorAll(sel)
 }
 
+func (d documentFragment) Children() []Element {
+	return (&BasicElement{&BasicNode{d.Value}}).Children()
+}
+
+func (d documentFragment) ChildrenLive() *HTMLCollection {
+	return (&BasicElement{&BasicNode{d.Value}}).ChildrenLive()
+}
+
+func (d documentFragment) ChildElementCount() int {
+	return (&BasicElement{&BasicNode{d.Value}}).ChildElementCount()
+}
+
+func (d documentFragment) FirstElementChild() Element {
+	return (&BasicElement{&BasicNode{d.Value}}).FirstElementChild()
+}
+
+func (d documentFragment) LastElementChild() Element {
+	return (&BasicElement{&BasicNode{d.Value}}).LastElementChild()
+}
+
+func (d documentFragment) Append(nodes ...interface{}) {
+	(&BasicElement{&BasicNode{d.Value}}).Append(nodes...)
+}
+
+func (d documentFragment) Prepend(nodes ...interface{}) {
+	(&BasicElement{&BasicNode{d.Value}}).Prepend(nodes...)
+}
+
+func (d documentFragment) ReplaceChildren(nodes ...interface{}) {
+	(&BasicElement{&BasicNode{d.Value}}).ReplaceChildren(nodes...)
+}
+
+func (d documentFragment) MoveBefore(node Node, child Node) {
+	(&BasicElement{&BasicNode{d.Value}}).MoveBefore(node, child)
+}
+
+func (d documentFragment) AppendErr(nodes ...interface{}) error {
+	return (&BasicElement{&BasicNode{d.Value}}).AppendErr(nodes...)
+}
+
+func (d documentFragment) PrependErr(nodes ...interface{}) error {
+	return (&BasicElement{&BasicNode{d.Value}}).PrependErr(nodes...)
+}
+
+func (d documentFragment) ReplaceChildrenErr(nodes ...interface{}) error {
+	return (&BasicElement{&BasicNode{d.Value}}).ReplaceChildrenErr(nodes...)
+}
+
+func (d documentFragment) MoveBeforeErr(node Node, child Node) error {
+	return (&BasicElement{&BasicNode{d.Value}}).MoveBeforeErr(node, child)
+}
+
+func (d documentFragment) QuerySelectorErr(sel string) (Element, error) {
+	return (&BasicElement{&BasicNode{d.Value}}).QuerySelectorErr(sel)
+}
+
+func (d documentFragment) QuerySelectorAllErr(sel string) ([]Element, error) {
+	return (&BasicElement{&BasicNode{d.Value}}).QuerySelectorAllErr(sel)
+}
+
+// ShadowRoot is the root node of a shadow DOM subtree, which is
+// rendered separately from the document's main DOM tree.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/ShadowRoot.
+type ShadowRoot interface {
+	DocumentFragment
+
+	ActiveElement() Element
+	AdoptedStyleSheets() []*CSSStyleSheet
+	SetAdoptedStyleSheets([]*CSSStyleSheet)
+	DelegatesFocus() bool
+	Host() Element
+	InnerHTML() string
+	SetInnerHTML(string)
+	SetHTMLUnsafe(string) error
+	Mode() ShadowRootMode
+	SlotAssignment() SlotAssignmentMode
+}
+
+type ShadowRootMode string
+
+const (
+	// ShadowRootOpen makes the shadow root accessible from outside of
+	// it, via Element.ShadowRoot.
+	ShadowRootOpen ShadowRootMode = "open"
+	// ShadowRootClosed hides the shadow root from outside of it.
+	ShadowRootClosed ShadowRootMode = "closed"
+)
+
+type SlotAssignmentMode string
+
+const (
+	// SlotAssignmentNamed assigns elements to slots according to their
+	// slot attributes.
+	SlotAssignmentNamed SlotAssignmentMode = "named"
+	// SlotAssignmentManual requires nodes to be assigned to slots with
+	// HTMLSlotElement.Assign.
+	SlotAssignmentManual SlotAssignmentMode = "manual"
+)
+
+// ShadowRootInit holds the options for Element.AttachShadow. The zero
+// value creates an open shadow root using named slot assignment.
+type ShadowRootInit struct {
+	Mode           ShadowRootMode
+	DelegatesFocus bool
+	SlotAssignment SlotAssignmentMode
+}
+
+type shadowRoot struct {
+	*documentFragment
+}
+
+// wrapShadowRoot wraps o, which is known to be a shadow root or null,
+// without checking its constructor.
+func wrapShadowRoot(o js.Value) ShadowRoot {
+	if o.IsNull() || o.IsUndefined() {
+		return nil
+	}
+	return &shadowRoot{&documentFragment{&BasicNode{o}}}
+}
+
+func (r *shadowRoot) ActiveElement() Element {
+	return wrapElement(r.Get("activeElement"))
+}
+
+func (r *shadowRoot) AdoptedStyleSheets() []*CSSStyleSheet {
+	return styleSheetsFromArray(r.Get("adoptedStyleSheets"))
+}
+
+func (r *shadowRoot) SetAdoptedStyleSheets(sheets []*CSSStyleSheet) {
+	r.Set("adoptedStyleSheets", styleSheetsToArray(sheets))
+}
+
+func (r *shadowRoot) DelegatesFocus() bool {
+	return r.Get("delegatesFocus").Bool()
+}
+
+func (r *shadowRoot) Host() Element {
+	return wrapElement(r.Get("host"))
+}
+
+func (r *shadowRoot) InnerHTML() string {
+	return r.Get("innerHTML").String()
+}
+
+func (r *shadowRoot) SetInnerHTML(s string) {
+	r.Set("innerHTML", s)
+}
+
+func (r *shadowRoot) SetHTMLUnsafe(html string) error {
+	return setHTMLUnsafe(r.Value, html)
+}
+
+func (r *shadowRoot) Mode() ShadowRootMode {
+	return ShadowRootMode(r.Get("mode").String())
+}
+
+func (r *shadowRoot) SlotAssignment() SlotAssignmentMode {
+	return SlotAssignmentMode(r.Get("slotAssignment").String())
+}
+
 type document struct {
 	*BasicNode
 }
@@ -610,6 +1194,120 @@ func (d *htmlDocument) ActiveElement() HTMLElement {
 	return wrapHTMLElement(d.Get("activeElement"))
 }
 
+func (d *htmlDocument) CurrentScript() Element {
+	return wrapElement(d.Get("currentScript"))
+}
+
+func (d *htmlDocument) FullscreenElement() Element {
+	return wrapElement(d.Get("fullscreenElement"))
+}
+
+func (d *htmlDocument) FullscreenEnabled() bool {
+	return d.Get("fullscreenEnabled").Truthy()
+}
+
+func (d *htmlDocument) Hidden() bool {
+	return d.Get("hidden").Bool()
+}
+
+func (d *htmlDocument) PointerLockElement() Element {
+	return wrapElement(d.Get("pointerLockElement"))
+}
+
+func (d *htmlDocument) VisibilityState() string {
+	return d.Get("visibilityState").String()
+}
+
+func (d *htmlDocument) ExecCommand(command string, showUI bool, value string) bool {
+	return d.Call("execCommand", command, showUI, value).Bool()
+}
+
+func (d *htmlDocument) ExitFullscreen() error {
+	// Like requestFullscreen, exitFullscreen doesn't return a promise in
+	// older browsers.
+	p, err := callValueRecover(d.Value, "exitFullscreen")
+	if err != nil || p.Type() != js.TypeObject {
+		return err
+	}
+	_, err = await(p)
+	return err
+}
+
+func (d *htmlDocument) ExitPointerLock() {
+	d.Call("exitPointerLock")
+}
+
+func (d *htmlDocument) HasFocus() bool {
+	return d.Call("hasFocus").Bool()
+}
+
+func (d *htmlDocument) QueryCommandState(command string) bool {
+	return d.Call("queryCommandState", command).Bool()
+}
+
+func (d *htmlDocument) StartViewTransition(update func()) *ViewTransition {
+	if d.Get("startViewTransition").IsUndefined() {
+		update()
+		done := js.Global().Get("Promise").Call("resolve")
+		o := js.Global().Get("Object").New()
+		o.Set("finished", done)
+		o.Set("ready", done)
+		o.Set("updateCallbackDone", done)
+		o.Set("skipTransition", js.Global().Get("Function").New())
+		return &ViewTransition{o}
+	}
+	fn := js.FuncOf(func(js.Value, []js.Value) interface{} {
+		update()
+		return nil
+	})
+	t := &ViewTransition{d.Call("startViewTransition", fn)}
+	var release js.Func
+	release = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		fn.Release()
+		release.Release()
+		return nil
+	})
+	t.Get("finished").Call("then", release, release)
+	return t
+}
+
+// ViewTransition is an animated transition between two states of the
+// page.
+//
+// The methods that return errors block until the corresponding
+// promise settles and must not be called directly from a JavaScript
+// callback such as an event listener.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/ViewTransition.
+type ViewTransition struct {
+	js.Value
+}
+
+// Finished waits for the transition to end.
+func (t *ViewTransition) Finished() error {
+	_, err := await(t.Get("finished"))
+	return err
+}
+
+// Ready waits for the transition to be about to start animating. It
+// returns an error if the transition cannot start.
+func (t *ViewTransition) Ready() error {
+	_, err := await(t.Get("ready"))
+	return err
+}
+
+// UpdateCallbackDone waits for the update function to have been
+// called.
+func (t *ViewTransition) UpdateCallbackDone() error {
+	_, err := await(t.Get("updateCallbackDone"))
+	return err
+}
+
+// SkipTransition skips the animation, but not the update.
+func (t *ViewTransition) SkipTransition() {
+	t.Call("skipTransition")
+}
+
 func (d *htmlDocument) Body() HTMLElement {
 	return wrapHTMLElement(d.Get("body"))
 }
@@ -623,7 +1321,7 @@ func (d *htmlDocument) SetCookie(s string) {
 }
 
 func (d *htmlDocument) DefaultView() Window {
-	return &window{d.Get("defaultView")}
+	return wrapWindow(d.Get("defaultView"))
 }
 
 func (d *htmlDocument) DesignMode() bool {
@@ -657,6 +1355,10 @@ func (d *htmlDocument) Forms() []*HTMLFormElement {
 	return els
 }
 
+func (d *htmlDocument) FormsLive() *HTMLCollection {
+	return &HTMLCollection{d.Get("forms")}
+}
+
 func (d *htmlDocument) Head() *HTMLHeadElement {
 	head := wrapElement(d.Get("head"))
 	if head == nil {
@@ -675,8 +1377,23 @@ func (d *htmlDocument) Images() []*HTMLImageElement {
 	return els
 }
 
+func (d *htmlDocument) ImagesLive() *HTMLCollection {
+	return &HTMLCollection{d.Get("images")}
+}
+
 func (d *htmlDocument) LastModified() time.Time {
-	return time.Unix(0, int64(d.Get("lastModified").Call("getTime").Int())*1000000)
+	// lastModified is a string of the form "MM/DD/YYYY hh:mm:ss" in
+	// local time, which Date doesn't have to understand.
+	s := d.Get("lastModified").String()
+	if t, err := time.ParseInLocation("01/02/2006 15:04:05", s, time.Local); err == nil {
+		return t
+	}
+	// Fall back to Date for browsers that deviate from the format.
+	ms := js.Global().Get("Date").New(s).Call("getTime").Float()
+	if math.IsNaN(ms) {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(ms)*int64(time.Millisecond))
 }
 
 func (d *htmlDocument) Links() []HTMLElement {
@@ -689,6 +1406,10 @@ func (d *htmlDocument) Links() []HTMLElement {
 	return els
 }
 
+func (d *htmlDocument) LinksLive() *HTMLCollection {
+	return &HTMLCollection{d.Get("links")}
+}
+
 func (d *htmlDocument) Location() *Location {
 	o := d.Get("location")
 	return &Location{Value: o, URLUtils: &URLUtils{Value: o}}
@@ -722,6 +1443,10 @@ func (d *htmlDocument) Scripts() []*HTMLScriptElement {
 	return els
 }
 
+func (d *htmlDocument) ScriptsLive() *HTMLCollection {
+	return &HTMLCollection{d.Get("scripts")}
+}
+
 func (d *htmlDocument) Title() string {
 	return d.Get("title").String()
 }
@@ -743,8 +1468,14 @@ func (d document) SetAsync(b bool) {
 }
 
 func (d document) Doctype() DocumentType {
-	// FIXME implement
-	panic("not implemented")
+	o := d.Get("doctype")
+	if o.IsNull() || o.IsUndefined() {
+		return nil
+	}
+	if dt, ok := wrapNode(o).(DocumentType); ok {
+		return dt
+	}
+	return &documentType{&BasicNode{o}}
 }
 
 func (d document) DocumentElement() Element {
@@ -756,8 +1487,7 @@ func (d document) DocumentURI() string {
 }
 
 func (d document) Implementation() DOMImplementation {
-	// FIXME implement
-	panic("not implemented")
+	return &domImplementation{d.Get("implementation")}
 }
 
 func (d document) LastStyleSheetSet() string {
@@ -794,6 +1524,25 @@ func (d document) CreateDocumentFragment() DocumentFragment {
 	return wrapDocumentFragment(d.Call("createDocumentFragment"))
 }
 
+// CreateTreeWalker creates a TreeWalker for the subtree of root. Only
+// nodes that match whatToShow, a combination of the Show constants,
+// and that are accepted by filter are visited. filter may be nil.
+// Unless filter is nil, the walker has to be released with Release
+// once it is no longer needed.
+func (d document) CreateTreeWalker(root Node, whatToShow uint32, filter NodeFilter) *TreeWalker {
+	fn, f := jsFilter(filter)
+	return &TreeWalker{Value: d.Call("createTreeWalker", root.Underlying(), whatToShow, f), filter: fn}
+}
+
+// CreateNodeIterator creates a NodeIterator for the subtree of root.
+// whatToShow and filter work like they do for CreateTreeWalker.
+// Unless filter is nil, the iterator has to be released with Release
+// once it is no longer needed.
+func (d document) CreateNodeIterator(root Node, whatToShow uint32, filter NodeFilter) *NodeIterator {
+	fn, f := jsFilter(filter)
+	return &NodeIterator{Value: d.Call("createNodeIterator", root.Underlying(), whatToShow, f), filter: fn}
+}
+
 func (d document) CreateElement(name string) Element {
 	return wrapElement(d.Call("createElement", name))
 }
@@ -810,6 +1559,26 @@ func (d document) ElementFromPoint(x, y int) Element {
 	return wrapElement(d.Call("elementFromPoint", x, y))
 }
 
+func (d document) ElementsFromPoint(x, y int) []Element {
+	return nodeListToElements(d.Call("elementsFromPoint", x, y))
+}
+
+func (d document) CharacterSet() string {
+	return d.Get("characterSet").String()
+}
+
+func (d document) ContentType() string {
+	return d.Get("contentType").String()
+}
+
+func (d document) CreateAttribute(name string) *Attr {
+	return &Attr{&BasicNode{d.Call("createAttribute", name)}}
+}
+
+func (d document) CreateComment(data string) *Comment {
+	return &Comment{&BasicNode{d.Call("createComment", data)}}
+}
+
 func (d document) EnableStyleSheetsForSet(name string) {
 	d.Call("enableStyleSheetsForSet", name)
 }
@@ -826,6 +1595,18 @@ func (d document) GetElementsByTagNameNS(ns, name string) []Element {
 	return (&BasicElement{&BasicNode{d.Value}}).GetElementsByTagNameNS(ns, name)
 }
 
+func (d document) GetElementsByClassNameLive(name string) *HTMLCollection {
+	return (&BasicElement{&BasicNode{d.Value}}).GetElementsByClassNameLive(name)
+}
+
+func (d document) GetElementsByTagNameLive(name string) *HTMLCollection {
+	return (&BasicElement{&BasicNode{d.Value}}).GetElementsByTagNameLive(name)
+}
+
+func (d document) GetElementsByTagNameNSLive(ns, name string) *HTMLCollection {
+	return (&BasicElement{&BasicNode{d.Value}}).GetElementsByTagNameNSLive(ns, name)
+}
+
 func (d document) GetElementByID(id string) Element {
 	return wrapElement(d.Call("getElementById", id))
 }
@@ -838,6 +1619,98 @@ func (d document) QuerySelectorAll(sel string) []Element {
 	return (&BasicElement{&BasicNode{d.Value}}).QuerySelectorAll(sel)
 }
 
+func (d document) Children() []Element {
+	return (&BasicElement{&BasicNode{d.Value}}).Children()
+}
+
+func (d document) ChildrenLive() *HTMLCollection {
+	return (&BasicElement{&BasicNode{d.Value}}).ChildrenLive()
+}
+
+func (d document) ChildElementCount() int {
+	return (&BasicElement{&BasicNode{d.Value}}).ChildElementCount()
+}
+
+func (d document) FirstElementChild() Element {
+	return (&BasicElement{&BasicNode{d.Value}}).FirstElementChild()
+}
+
+func (d document) LastElementChild() Element {
+	return (&BasicElement{&BasicNode{d.Value}}).LastElementChild()
+}
+
+func (d document) Append(nodes ...interface{}) {
+	(&BasicElement{&BasicNode{d.Value}}).Append(nodes...)
+}
+
+func (d document) Prepend(nodes ...interface{}) {
+	(&BasicElement{&BasicNode{d.Value}}).Prepend(nodes...)
+}
+
+func (d document) ReplaceChildren(nodes ...interface{}) {
+	(&BasicElement{&BasicNode{d.Value}}).ReplaceChildren(nodes...)
+}
+
+func (d document) MoveBefore(node Node, child Node) {
+	(&BasicElement{&BasicNode{d.Value}}).MoveBefore(node, child)
+}
+
+func (d document) AppendErr(nodes ...interface{}) error {
+	return (&BasicElement{&BasicNode{d.Value}}).AppendErr(nodes...)
+}
+
+func (d document) PrependErr(nodes ...interface{}) error {
+	return (&BasicElement{&BasicNode{d.Value}}).PrependErr(nodes...)
+}
+
+func (d document) ReplaceChildrenErr(nodes ...interface{}) error {
+	return (&BasicElement{&BasicNode{d.Value}}).ReplaceChildrenErr(nodes...)
+}
+
+func (d document) MoveBeforeErr(node Node, child Node) error {
+	return (&BasicElement{&BasicNode{d.Value}}).MoveBeforeErr(node, child)
+}
+
+func (d document) QuerySelectorErr(sel string) (Element, error) {
+	return (&BasicElement{&BasicNode{d.Value}}).QuerySelectorErr(sel)
+}
+
+func (d document) QuerySelectorAllErr(sel string) ([]Element, error) {
+	return (&BasicElement{&BasicNode{d.Value}}).QuerySelectorAllErr(sel)
+}
+
+func (d document) AdoptNodeErr(node Node) (Node, error) {
+	o, err := callValueRecover(d.Value, "adoptNode", node.Underlying())
+	if err != nil {
+		return nil, err
+	}
+	return wrapNode(o), nil
+}
+
+func (d document) ImportNodeErr(node Node, deep bool) (Node, error) {
+	o, err := callValueRecover(d.Value, "importNode", node.Underlying(), deep)
+	if err != nil {
+		return nil, err
+	}
+	return wrapNode(o), nil
+}
+
+func (d document) CreateElementErr(name string) (Element, error) {
+	o, err := callValueRecover(d.Value, "createElement", name)
+	if err != nil {
+		return nil, err
+	}
+	return wrapElement(o), nil
+}
+
+func (d document) CreateElementNSErr(ns string, name string) (Element, error) {
+	o, err := callValueRecover(d.Value, "createElementNS", ns, name)
+	if err != nil {
+		return nil, err
+	}
+	return wrapElement(o), nil
+}
+
 type URLUtils struct {
 	js.Value
 }
@@ -865,19 +1738,55 @@ func (u *URLUtils) SetHash(v string)     { u.Set("hash", v) }
 func (u *URLUtils) SetUsername(v string) { u.Set("username", v) }
 func (u *URLUtils) SetPassword(v string) { u.Set("password", v) }
 
-// TODO Location methods
+// URL parses the URL as a net/url URL.
+func (u *URLUtils) URL() (*url.URL, error) { return url.Parse(u.Href()) }
+
+// SetURL sets the URL from a net/url URL.
+func (u *URLUtils) SetURL(v *url.URL) { u.SetHref(v.String()) }
 
+// Location is the URL of a window's document. Setting any of its
+// properties navigates to the new URL.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/Location.
 type Location struct {
 	js.Value
 	*URLUtils
 }
 
+// Assign navigates to u, adding an entry to the session history.
+func (l *Location) Assign(u string) { l.Call("assign", u) }
+
+// Replace navigates to u, replacing the current entry in the session
+// history.
+func (l *Location) Replace(u string) { l.Call("replace", u) }
+
+// Reload reloads the current URL.
+func (l *Location) Reload() { l.Call("reload") }
+
+// AncestorOrigins returns the origins of the documents that the
+// document is embedded in, starting with the parent document.
+func (l *Location) AncestorOrigins() []string {
+	o := l.Get("ancestorOrigins")
+	if o.IsUndefined() {
+		// Not supported by Firefox.
+		return nil
+	}
+	out := make([]string, o.Length())
+	for i := range out {
+		out[i] = o.Call("item", i).String()
+	}
+	return out
+}
+
 type HTMLElement interface {
 	Element
 	GlobalEventHandlers
 
 	AccessKey() string
+	// Dataset returns a copy of the element's data attributes. Use
+	// DatasetLive to modify them.
 	Dataset() map[string]string
+	DatasetLive() *DOMStringMap
 	SetAccessKey(string)
 	AccessKeyLabel() string
 	SetAccessKeyLabel(string)
@@ -913,8 +1822,24 @@ type Window interface {
 	EventTarget
 
 	Console() *Console
+	Crypto() *Crypto
+	CustomElements() *CustomElementRegistry
 	Document() Document
+	// Fetch sends req and returns the response once its headers have
+	// arrived. Cancelling ctx aborts the request, including the reading
+	// of the response body. Like all blocking functions, Fetch must not
+	// be called directly from a JavaScript callback such as an event
+	// listener.
+	Fetch(ctx context.Context, req *Request) (*Response, error)
 	FrameElement() Element
+	// IndexedDB returns the IndexedDB factory, or nil if IndexedDB
+	// isn't available.
+	IndexedDB() *IDBFactory
+	// LocalStorage returns the origin's local storage. It panics if
+	// access is denied, for example because the user has disabled
+	// cookies; use LocalStorageErr to handle that case.
+	LocalStorage() *Storage
+	LocalStorageErr() (*Storage, error)
 	Location() *Location
 	Name() string
 	SetName(string)
@@ -935,6 +1860,10 @@ type Window interface {
 	History() History
 	Navigator() Navigator
 	Screen() *Screen
+	// SessionStorage returns the storage of the current tab and origin.
+	// Like LocalStorage, it panics if access is denied.
+	SessionStorage() *Storage
+	SessionStorageErr() (*Storage, error)
 	Alert(string)
 	Back()
 	Blur()
@@ -952,9 +1881,29 @@ type Window interface {
 	MoveTo(x, y int)
 	Open(url, name, features string) Window
 	OpenDialog(url, name, features string, args []interface{}) Window
-	PostMessage(message string, target string, transfer []interface{})
+	// PostMessage sends a copy of message to the window, which receives
+	// it as a MessageEvent, if the window's origin matches
+	// targetOrigin. It panics if message cannot be copied; use
+	// PostMessageErr to handle that case.
+	//
+	// message may be any value supported by js.ValueOf, a []byte, which
+	// is sent as a Uint8Array, or a value that can be encoded as JSON,
+	// which is sent as the equivalent JavaScript object; []byte fields
+	// of such values become base64 strings, as with encoding/json. The
+	// elements of a map[string]interface{} or []interface{} are
+	// converted like message itself. transfer lists
+	// objects, such as ArrayBuffers and MessagePorts, whose ownership
+	// is transferred to the receiver; it may be nil.
+	PostMessage(message interface{}, targetOrigin string, transfer []interface{})
+	// PostMessageErr is like PostMessage, but returns ErrDataClone or
+	// the error that encoding message returned.
+	PostMessageErr(message interface{}, targetOrigin string, transfer []interface{}) error
+	Performance() *Performance
 	Print()
 	Prompt(prompt string, initial string) string
+	// QueueMicrotask schedules fn to be called once the current task
+	// and all previously queued microtasks have finished.
+	QueueMicrotask(fn func())
 	RequestAnimationFrame(callback func(time.Duration)) int
 	ResizeBy(dw, dh int)
 	ResizeTo(w, h int)
@@ -962,6 +1911,9 @@ type Window interface {
 	ScrollBy(dx, dy int)
 	ScrollByLines(int)
 	ScrollTo(x, y int)
+	ScrollWithOptions(ScrollToOptions)
+	ScrollByWithOptions(ScrollToOptions)
+	ScrollToWithOptions(ScrollToOptions)
 	SetCursor(name string)
 	SetInterval(fn func(), delay int) int
 	SetTimeout(fn func(), delay int) int
@@ -978,6 +1930,14 @@ func (w *window) Console() *Console {
 	return &Console{w.Get("console")}
 }
 
+func (w *window) Crypto() *Crypto {
+	return &Crypto{w.Get("crypto")}
+}
+
+func (w *window) CustomElements() *CustomElementRegistry {
+	return &CustomElementRegistry{Value: w.Get("customElements"), global: w.Value}
+}
+
 func (w *window) Document() Document {
 	return wrapDocument(w.Get("document"))
 }
@@ -986,6 +1946,50 @@ func (w *window) FrameElement() Element {
 	return wrapElement(w.Get("frameElement"))
 }
 
+func (w *window) Fetch(ctx context.Context, req *Request) (*Response, error) {
+	return fetch(ctx, w.Value, req)
+}
+
+func (w *window) IndexedDB() *IDBFactory {
+	o := w.Get("indexedDB")
+	if o.IsNull() || o.IsUndefined() {
+		return nil
+	}
+	return &IDBFactory{o}
+}
+
+func (w *window) LocalStorage() *Storage {
+	s, err := w.LocalStorageErr()
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (w *window) LocalStorageErr() (*Storage, error) {
+	o, err := getRecover(w.Value, "localStorage")
+	if err != nil {
+		return nil, err
+	}
+	return wrapStorage(o), nil
+}
+
+func (w *window) SessionStorage() *Storage {
+	s, err := w.SessionStorageErr()
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (w *window) SessionStorageErr() (*Storage, error) {
+	o, err := getRecover(w.Value, "sessionStorage")
+	if err != nil {
+		return nil, err
+	}
+	return wrapStorage(o), nil
+}
+
 func (w *window) Location() *Location {
 	o := w.Get("location")
 	return &Location{Value: o, URLUtils: &URLUtils{Value: o}}
@@ -1012,7 +2016,7 @@ func (w *window) Length() int {
 }
 
 func (w *window) Opener() Window {
-	return &window{w.Get("opener")}
+	return wrapWindow(w.Get("opener"))
 }
 
 func (w *window) OuterHeight() int {
@@ -1032,7 +2036,7 @@ func (w *window) ScrollY() int {
 }
 
 func (w *window) Parent() Window {
-	return &window{w.Get("parent")}
+	return wrapWindow(w.Get("parent"))
 }
 
 func (w *window) ScreenX() int {
@@ -1052,7 +2056,7 @@ func (w *window) ScrollMaxY() int {
 }
 
 func (w *window) Top() Window {
-	return &window{w.Get("top")}
+	return wrapWindow(w.Get("top"))
 }
 
 func (w *window) History() History {
@@ -1135,15 +2139,25 @@ func (w *window) MoveTo(x, y int) {
 }
 
 func (w *window) Open(url, name, features string) Window {
-	return &window{w.Call("open", url, name, features)}
+	return wrapWindow(w.Call("open", url, name, features))
 }
 
 func (w *window) OpenDialog(url, name, features string, args []interface{}) Window {
-	return &window{w.Call("openDialog", url, name, features, args)}
+	return wrapWindow(w.Call("openDialog", url, name, features, args))
+}
+
+func (w *window) PostMessage(message interface{}, targetOrigin string, transfer []interface{}) {
+	if err := w.PostMessageErr(message, targetOrigin, transfer); err != nil {
+		panic(err)
+	}
 }
 
-func (w *window) PostMessage(message string, target string, transfer []interface{}) {
-	w.Call("postMessage", message, target, transfer)
+func (w *window) PostMessageErr(message interface{}, targetOrigin string, transfer []interface{}) error {
+	return postMessage(w.Value, message, transfer, targetOrigin)
+}
+
+func (w *window) Performance() *Performance {
+	return &Performance{w.Get("performance")}
 }
 
 func (w *window) Print() {
@@ -1154,6 +2168,16 @@ func (w *window) Prompt(prompt string, initial string) string {
 	return w.Call("prompt", prompt, initial).String()
 }
 
+func (w *window) QueueMicrotask(fn func()) {
+	var wrapper js.Func
+	wrapper = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		defer wrapper.Release()
+		fn()
+		return nil
+	})
+	w.Call("queueMicrotask", wrapper)
+}
+
 func (w *window) ResizeBy(dw, dh int) {
 	w.Call("resizeBy", dw, dh)
 }
@@ -1178,6 +2202,18 @@ func (w *window) ScrollTo(x, y int) {
 	w.Call("scrollTo", x, y)
 }
 
+func (w *window) ScrollWithOptions(opts ScrollToOptions) {
+	w.Call("scroll", opts.toJS())
+}
+
+func (w *window) ScrollByWithOptions(opts ScrollToOptions) {
+	w.Call("scrollBy", opts.toJS())
+}
+
+func (w *window) ScrollToWithOptions(opts ScrollToOptions) {
+	w.Call("scrollTo", opts.toJS())
+}
+
 func (w *window) SetCursor(name string) {
 	w.Call("setCursor", name)
 }
@@ -1396,10 +2432,169 @@ type Console struct {
 }
 
 type SVGDocument interface{}
-type DocumentType interface{}
-type DOMImplementation interface{}
+
+// DocumentType represents a document's doctype, such as
+// <!DOCTYPE html>.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/DocumentType.
+type DocumentType interface {
+	Node
+
+	Name() string
+	PublicID() string
+	SystemID() string
+}
+
+type documentType struct {
+	*BasicNode
+}
+
+func (d *documentType) Name() string     { return d.Get("name").String() }
+func (d *documentType) PublicID() string { return d.Get("publicId").String() }
+func (d *documentType) SystemID() string { return d.Get("systemId").String() }
+
+// DOMImplementation creates documents that are independent of the
+// current one. Such documents are inert: scripts don't run, and
+// resources such as images aren't loaded.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/DOMImplementation.
+type DOMImplementation interface {
+	// CreateHTMLDocument creates a new HTML document with a basic
+	// structure, including a <title> set to title.
+	CreateHTMLDocument(title string) Document
+	// CreateDocument creates a new XML document. If qualifiedName is
+	// not empty, the document gets a root element of that name in
+	// namespace. doctype may be nil.
+	CreateDocument(namespace, qualifiedName string, doctype DocumentType) (Document, error)
+	CreateDocumentType(qualifiedName, publicID, systemID string) (DocumentType, error)
+}
+
+type domImplementation struct {
+	js.Value
+}
+
+func (impl *domImplementation) CreateHTMLDocument(title string) Document {
+	return wrapDocument(impl.Call("createHTMLDocument", title))
+}
+
+func (impl *domImplementation) CreateDocument(namespace, qualifiedName string, doctype DocumentType) (Document, error) {
+	var ns, dt interface{}
+	if namespace != "" {
+		ns = namespace
+	}
+	if doctype != nil {
+		dt = doctype.Underlying()
+	}
+	o, err := callValueRecover(impl.Value, "createDocument", ns, qualifiedName, dt)
+	if err != nil {
+		return nil, err
+	}
+	return wrapDocument(o), nil
+}
+
+func (impl *domImplementation) CreateDocumentType(qualifiedName, publicID, systemID string) (DocumentType, error) {
+	o, err := callValueRecover(impl.Value, "createDocumentType", qualifiedName, publicID, systemID)
+	if err != nil {
+		return nil, err
+	}
+	return &documentType{&BasicNode{o}}, nil
+}
+
+// DOMParser parses HTML and XML source code into a new, inert
+// document.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/DOMParser.
+type DOMParser struct {
+	js.Value
+}
+
+func NewDOMParser() *DOMParser {
+	return &DOMParser{js.Global().Get("DOMParser").New()}
+}
+
+// ParseFromString parses s as a document of type mimeType, which must
+// be one of "text/html", "text/xml", "application/xml",
+// "application/xhtml+xml" and "image/svg+xml". Malformed XML doesn't
+// cause an error; instead, the returned document contains a
+// <parsererror> element describing the problem.
+func (p *DOMParser) ParseFromString(s, mimeType string) Document {
+	return wrapDocument(p.Call("parseFromString", s, mimeType))
+}
+
+// XMLSerializer converts DOM trees to XML.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/XMLSerializer.
+type XMLSerializer struct {
+	js.Value
+}
+
+func NewXMLSerializer() *XMLSerializer {
+	return &XMLSerializer{js.Global().Get("XMLSerializer").New()}
+}
+
+// SerializeToString returns the XML serialization of the subtree of
+// n, including n itself.
+func (s *XMLSerializer) SerializeToString(n Node) (string, error) {
+	o, err := callValueRecover(s.Value, "serializeToString", n.Underlying())
+	if err != nil {
+		return "", err
+	}
+	return o.String(), nil
+}
+
+// ParseHTMLUnsafe parses html into a new, inert HTML document. Unlike
+// DOMParser, it also parses declarative shadow roots. The result is
+// not sanitized. It returns ErrNotSupported if the browser doesn't
+// support Document.parseHTMLUnsafe.
+func ParseHTMLUnsafe(html string) (Document, error) {
+	ctor := js.Global().Get("Document")
+	if ctor.Get("parseHTMLUnsafe").Type() != js.TypeFunction {
+		return nil, ErrNotSupported
+	}
+	return wrapDocument(ctor.Call("parseHTMLUnsafe", html)), nil
+}
+
 type StyleSheet interface{}
-type CSSStyleSheet interface{}
+
+// CSSStyleSheet represents a single CSS stylesheet. Stylesheets
+// created with NewCSSStyleSheet can be shared between documents and
+// shadow roots with SetAdoptedStyleSheets.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/CSSStyleSheet.
+type CSSStyleSheet struct {
+	js.Value
+}
+
+// NewCSSStyleSheet creates a new, empty stylesheet.
+func NewCSSStyleSheet() *CSSStyleSheet {
+	return &CSSStyleSheet{js.Global().Get("CSSStyleSheet").New()}
+}
+
+func (s *CSSStyleSheet) Disabled() bool     { return s.Get("disabled").Bool() }
+func (s *CSSStyleSheet) SetDisabled(v bool) { s.Set("disabled", v) }
+
+// ReplaceSync replaces the content of the stylesheet with text. Rules
+// using @import are ignored. It returns an error if the stylesheet
+// wasn't created with NewCSSStyleSheet.
+func (s *CSSStyleSheet) ReplaceSync(text string) error {
+	return callRecover(s.Value, "replaceSync", text)
+}
+
+func styleSheetsFromArray(o js.Value) []*CSSStyleSheet {
+	out := make([]*CSSStyleSheet, o.Length())
+	for i := range out {
+		out[i] = &CSSStyleSheet{o.Index(i)}
+	}
+	return out
+}
+
+func styleSheetsToArray(sheets []*CSSStyleSheet) []interface{} {
+	out := make([]interface{}, len(sheets))
+	for i, sheet := range sheets {
+		out[i] = sheet.Value
+	}
+	return out
+}
 
 type Node interface {
 	EventTarget
@@ -1407,6 +2602,7 @@ type Node interface {
 	Underlying() js.Value
 	BaseURI() string
 	ChildNodes() []Node
+	ChildNodesLive() *NodeList
 	FirstChild() Node
 	LastChild() Node
 	NextSibling() Node
@@ -1433,6 +2629,14 @@ type Node interface {
 	Normalize()
 	RemoveChild(Node)
 	ReplaceChild(newChild, oldChild Node)
+
+	// AppendChildErr, InsertBeforeErr, RemoveChildErr and
+	// ReplaceChildErr are like their counterparts without the Err
+	// suffix, but return DOMExceptions as errors instead of panicking.
+	AppendChildErr(Node) error
+	InsertBeforeErr(which Node, before Node) error
+	RemoveChildErr(Node) error
+	ReplaceChildErr(newChild, oldChild Node) error
 }
 
 // Type BasicNode implements the Node interface and is embedded by
@@ -1471,6 +2675,10 @@ func (n *BasicNode) ChildNodes() []Node {
 	return nodeListToNodes(n.Get("childNodes"))
 }
 
+func (n *BasicNode) ChildNodesLive() *NodeList {
+	return &NodeList{n.Get("childNodes")}
+}
+
 func (n *BasicNode) FirstChild() Node {
 	return wrapNode(n.Get("firstChild"))
 }
@@ -1500,8 +2708,7 @@ func (n *BasicNode) SetNodeValue(s string) {
 }
 
 func (n *BasicNode) OwnerDocument() Document {
-	// FIXME implement
-	panic("not implemented")
+	return wrapDocument(n.Get("ownerDocument"))
 }
 
 func (n *BasicNode) ParentNode() Node {
@@ -1589,25 +2796,64 @@ func (n *BasicNode) ReplaceChild(newChild, oldChild Node) {
 	n.Call("replaceChild", newChild.Underlying(), oldChild.Underlying())
 }
 
+func (n *BasicNode) AppendChildErr(newchild Node) error {
+	return callRecover(n.Value, "appendChild", newchild.Underlying())
+}
+
+func (n *BasicNode) InsertBeforeErr(which Node, before Node) error {
+	var o interface{}
+	if before != nil {
+		o = before.Underlying()
+	}
+	return callRecover(n.Value, "insertBefore", which.Underlying(), o)
+}
+
+func (n *BasicNode) RemoveChildErr(other Node) error {
+	return callRecover(n.Value, "removeChild", other.Underlying())
+}
+
+func (n *BasicNode) ReplaceChildErr(newChild, oldChild Node) error {
+	return callRecover(n.Value, "replaceChild", newChild.Underlying(), oldChild.Underlying())
+}
+
 type Element interface {
 	Node
 	ParentNode
 	ChildNode
 
+	AssignedSlot() *HTMLSlotElement
+	AttachShadow(ShadowRootInit) ShadowRoot
 	Attributes() map[string]string
+	CheckVisibility(CheckVisibilityOptions) bool
 	Class() *TokenList
+	ClientHeight() int
+	ClientLeft() int
+	ClientTop() int
+	ClientWidth() int
 	Closest(string) Element
 	ID() string
 	SetID(string)
 	TagName() string
-	GetAttribute(string) string                   // TODO can attributes only be strings?
-	GetAttributeNS(ns string, name string) string // can attributes only be strings?
+	GetAttribute(string) string
+	GetAttributeNS(ns string, name string) string
+	// GetAttributeOK returns the value of an attribute and whether the
+	// attribute exists, telling a missing attribute apart from an empty
+	// one.
+	GetAttributeOK(name string) (string, bool)
+	GetAttributeNames() []string
 	GetBoundingClientRect() *Rect
+	GetClientRects() []*Rect
 	GetElementsByClassName(string) []Element
 	GetElementsByTagName(string) []Element
 	GetElementsByTagNameNS(ns string, name string) []Element
+	GetElementsByClassNameLive(string) *HTMLCollection
+	GetElementsByTagNameLive(string) *HTMLCollection
+	GetElementsByTagNameNSLive(ns string, name string) *HTMLCollection
 	HasAttribute(string) bool
 	HasAttributeNS(ns string, name string) bool
+	InsertAdjacentElement(pos AdjacentPosition, el Element) Element
+	InsertAdjacentHTML(pos AdjacentPosition, html string)
+	InsertAdjacentText(pos AdjacentPosition, text string)
 	Matches(string) bool
 	QuerySelector(string) Element
 	QuerySelectorAll(string) []Element
@@ -1616,10 +2862,141 @@ type Element interface {
 	RemoveAttributeNS(ns string, name string)
 	SetAttribute(name string, value string)
 	SetAttributeNS(ns string, name string, value string)
+	RequestFullscreen(FullscreenOptions) error
+	RequestPointerLock(unadjustedMovement bool) error
+	// ToggleAttribute removes a boolean attribute if it exists and adds
+	// it otherwise. It reports whether the attribute exists afterwards.
+	ToggleAttribute(name string) bool
+	// ToggleAttributeForce adds the attribute if force is true and
+	// removes it otherwise. It reports whether the attribute exists
+	// afterwards, which is force.
+	ToggleAttributeForce(name string, force bool) bool
+	ScrollWithOptions(ScrollToOptions)
+	ScrollByWithOptions(ScrollToOptions)
+	ScrollToWithOptions(ScrollToOptions)
+	ScrollIntoView(ScrollIntoViewOptions)
+	ScrollHeight() int
+	ScrollLeft() float64
+	SetScrollLeft(float64)
+	ScrollTop() float64
+	SetScrollTop(float64)
+	ScrollWidth() int
 	InnerHTML() string
 	SetInnerHTML(string)
 	OuterHTML() string
 	SetOuterHTML(string)
+	SetHTMLUnsafe(string) error
+	ShadowRoot() ShadowRoot
+
+	AttachShadowErr(ShadowRootInit) (ShadowRoot, error)
+	ClosestErr(string) (Element, error)
+	InsertAdjacentElementErr(pos AdjacentPosition, el Element) (Element, error)
+	InsertAdjacentHTMLErr(pos AdjacentPosition, html string) error
+	InsertAdjacentTextErr(pos AdjacentPosition, text string) error
+	MatchesErr(string) (bool, error)
+	QuerySelectorErr(string) (Element, error)
+	QuerySelectorAllErr(string) ([]Element, error)
+	SetAttributeErr(name string, value string) error
+	SetAttributeNSErr(ns string, name string, value string) error
+	SetInnerHTMLErr(string) error
+	SetOuterHTMLErr(string) error
+	ToggleAttributeErr(name string) (bool, error)
+	ToggleAttributeForceErr(name string, force bool) (bool, error)
+}
+
+// FullscreenOptions are the options for Element.RequestFullscreen.
+type FullscreenOptions struct {
+	// NavigationUI is "hide", "show" or, if empty, "auto". It controls
+	// whether browsers show their navigation UI, such as on mobile
+	// devices, while in fullscreen mode.
+	NavigationUI string
+}
+
+// AdjacentPosition is a position relative to an element, for the
+// InsertAdjacent methods of Element.
+type AdjacentPosition string
+
+const (
+	// AdjacentBeforeBegin is before the element itself.
+	AdjacentBeforeBegin AdjacentPosition = "beforebegin"
+	// AdjacentAfterBegin is inside the element, before its first
+	// child.
+	AdjacentAfterBegin AdjacentPosition = "afterbegin"
+	// AdjacentBeforeEnd is inside the element, after its last child.
+	AdjacentBeforeEnd AdjacentPosition = "beforeend"
+	// AdjacentAfterEnd is after the element itself.
+	AdjacentAfterEnd AdjacentPosition = "afterend"
+)
+
+// ScrollBehavior determines whether scrolling is animated.
+type ScrollBehavior string
+
+const (
+	// ScrollAuto uses the scroll-behavior CSS property of the
+	// scrolled element.
+	ScrollAuto    ScrollBehavior = "auto"
+	ScrollSmooth  ScrollBehavior = "smooth"
+	ScrollInstant ScrollBehavior = "instant"
+)
+
+// ScrollLogicalPosition is the alignment of an element within the
+// visible area of its scroll container.
+type ScrollLogicalPosition string
+
+const (
+	ScrollStart   ScrollLogicalPosition = "start"
+	ScrollCenter  ScrollLogicalPosition = "center"
+	ScrollEnd     ScrollLogicalPosition = "end"
+	ScrollNearest ScrollLogicalPosition = "nearest"
+)
+
+// ScrollToOptions are the options for scrolling elements and windows.
+// Left and Top are the coordinates to scroll to, or the amount to
+// scroll by for ScrollByWithOptions. A nil Left or Top leaves the
+// position along that axis unchanged. An empty Behavior means
+// ScrollAuto.
+type ScrollToOptions struct {
+	Left     *float64
+	Top      *float64
+	Behavior ScrollBehavior
+}
+
+func (opts ScrollToOptions) toJS() map[string]interface{} {
+	o := map[string]interface{}{}
+	if opts.Left != nil {
+		o["left"] = *opts.Left
+	}
+	if opts.Top != nil {
+		o["top"] = *opts.Top
+	}
+	if opts.Behavior != "" {
+		o["behavior"] = string(opts.Behavior)
+	}
+	return o
+}
+
+// ScrollIntoViewOptions are the options for Element.ScrollIntoView.
+// An empty Behavior means ScrollAuto, an empty Block ScrollStart and
+// an empty Inline ScrollNearest.
+type ScrollIntoViewOptions struct {
+	Behavior ScrollBehavior
+	// Block is the vertical alignment.
+	Block ScrollLogicalPosition
+	// Inline is the horizontal alignment.
+	Inline ScrollLogicalPosition
+}
+
+// CheckVisibilityOptions are the options for Element.CheckVisibility,
+// which selects the checks beyond the element being rendered.
+type CheckVisibilityOptions struct {
+	// OpacityProperty makes elements with an opacity of 0 invisible.
+	OpacityProperty bool
+	// VisibilityProperty makes elements invisible whose visibility
+	// CSS property makes them so.
+	VisibilityProperty bool
+	// ContentVisibilityAuto makes elements invisible that are skipped
+	// because of content-visibility: auto.
+	ContentVisibilityAuto bool
 }
 
 // Rect represents a rectangle.
@@ -1647,13 +3024,73 @@ func (r *Rect) SetRight(v float64)  { r.Set("right", v) }
 func (r *Rect) SetBottom(v float64) { r.Set("bottom", v) }
 func (r *Rect) SetLeft(v float64)   { r.Set("left", v) }
 
+// ParentNode contains methods that are specific to nodes that can
+// have children: elements, documents and document fragments.
+//
+// Methods that accept nodes as ...interface{} accept both Nodes and
+// strings, which are inserted as Text nodes. They panic on values of
+// any other type.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/Element#instance_methods.
 type ParentNode interface {
-	// No properties/methods that aren't experimental
-}
-
+	Children() []Element
+	ChildrenLive() *HTMLCollection
+	ChildElementCount() int
+	FirstElementChild() Element
+	LastElementChild() Element
+	// Append inserts nodes after the last child.
+	Append(nodes ...interface{})
+	// Prepend inserts nodes before the first child.
+	Prepend(nodes ...interface{})
+	// ReplaceChildren replaces all children with nodes.
+	ReplaceChildren(nodes ...interface{})
+	// MoveBefore moves node before child, or to the end if child is
+	// nil, without removing and reinserting it. Unlike InsertBefore,
+	// this preserves the state of the node, such as focus or the
+	// playback of media.
+	MoveBefore(node Node, child Node)
+
+	AppendErr(nodes ...interface{}) error
+	PrependErr(nodes ...interface{}) error
+	ReplaceChildrenErr(nodes ...interface{}) error
+	MoveBeforeErr(node Node, child Node) error
+}
+
+// ChildNode contains methods that are specific to nodes that can have
+// a parent.
 type ChildNode interface {
 	PreviousElementSibling() Element
 	NextElementSibling() Element
+	// Before inserts nodes, which may be Nodes or strings, before this
+	// node.
+	Before(nodes ...interface{})
+	// After inserts nodes, which may be Nodes or strings, after this
+	// node.
+	After(nodes ...interface{})
+	// ReplaceWith replaces this node with nodes, which may be Nodes or
+	// strings.
+	ReplaceWith(nodes ...interface{})
+
+	BeforeErr(nodes ...interface{}) error
+	AfterErr(nodes ...interface{}) error
+	ReplaceWithErr(nodes ...interface{}) error
+}
+
+// nodesOrStrings converts the arguments of ParentNode and ChildNode
+// methods to the values expected by JavaScript.
+func nodesOrStrings(nodes []interface{}) []interface{} {
+	out := make([]interface{}, len(nodes))
+	for i, n := range nodes {
+		switch n := n.(type) {
+		case Node:
+			out[i] = n.Underlying()
+		case string:
+			out[i] = n
+		default:
+			panic(fmt.Sprintf("dom: %T is neither a Node nor a string", n))
+		}
+	}
+	return out
 }
 
 // Type BasicHTMLElement implements the HTMLElement interface and is
@@ -1677,6 +3114,103 @@ func (e *BasicHTMLElement) Dataset() map[string]string {
 	return data
 }
 
+// DatasetLive returns the element's data attributes as a
+// DOMStringMap, which reads from and writes to the element directly.
+func (e *BasicHTMLElement) DatasetLive() *DOMStringMap {
+	return &DOMStringMap{e.Get("dataset")}
+}
+
+// DOMStringMap provides access to an element's data attributes. Keys
+// are the camelCase names of the attributes without their data-
+// prefix, so that the key fooBar corresponds to the attribute
+// data-foo-bar. DatasetKey and DatasetAttributeName convert between the
+// two.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/DOMStringMap.
+type DOMStringMap struct {
+	js.Value
+}
+
+// Get returns the value for key and whether it exists.
+func (m *DOMStringMap) Get(key string) (string, bool) {
+	if !m.Has(key) {
+		return "", false
+	}
+	return m.Value.Get(key).String(), true
+}
+
+// Has reports whether key exists. Properties that the map inherits,
+// such as toString, aren't keys.
+func (m *DOMStringMap) Has(key string) bool {
+	return js.Global().Get("Object").Get("prototype").Get("hasOwnProperty").Call("call", m.Value, key).Bool()
+}
+
+// Set sets key to value, adding the corresponding attribute if
+// necessary. It panics if key isn't a valid key; use SetErr to handle
+// that case.
+func (m *DOMStringMap) Set(key, value string) {
+	if err := m.SetErr(key, value); err != nil {
+		panic(err)
+	}
+}
+
+// SetErr is like Set, but returns ErrSyntax if key contains a hyphen
+// followed by a lowercase letter.
+func (m *DOMStringMap) SetErr(key, value string) error { return setRecover(m.Value, key, value) }
+
+// Delete removes key and its attribute.
+func (m *DOMStringMap) Delete(key string) { m.Value.Delete(key) }
+
+// Keys returns all keys, in the order of the element's attributes.
+func (m *DOMStringMap) Keys() []string { return jsKeys(m.Value) }
+
+// DatasetKey returns the DOMStringMap key for the attribute attr,
+// such as fooBar for data-foo-bar. ok is false if attr isn't a data
+// attribute.
+func DatasetKey(attr string) (key string, ok bool) {
+	if !strings.HasPrefix(attr, "data-") {
+		return "", false
+	}
+	for _, r := range attr {
+		if r >= 'A' && r <= 'Z' {
+			return "", false
+		}
+	}
+	name := attr[len("data-"):]
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '-' && i+1 < len(name) && name[i+1] >= 'a' && name[i+1] <= 'z' {
+			b.WriteByte(name[i+1] - 'a' + 'A')
+			i++
+			continue
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String(), true
+}
+
+// DatasetAttributeName returns the name of the data attribute for the
+// DOMStringMap key key, such as data-foo-bar for fooBar. ok is false if
+// key contains a hyphen followed by a lowercase letter, which no
+// attribute maps to.
+func DatasetAttributeName(key string) (attr string, ok bool) {
+	var b strings.Builder
+	b.WriteString("data-")
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c == '-' && i+1 < len(key) && key[i+1] >= 'a' && key[i+1] <= 'z':
+			return "", false
+		case c >= 'A' && c <= 'Z':
+			b.WriteByte('-')
+			b.WriteByte(c - 'A' + 'a')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), true
+}
+
 // jsKeys returns the keys of the given JavaScript object.
 func jsKeys(o js.Value) []string {
 	if o.IsNull() || o.IsUndefined() {
@@ -1807,11 +3341,102 @@ func (e *BasicElement) Attributes() map[string]string {
 	return attrs
 }
 
+func (e *BasicElement) AssignedSlot() *HTMLSlotElement {
+	o := e.Get("assignedSlot")
+	if o.IsNull() || o.IsUndefined() {
+		return nil
+	}
+	// A registered wrapper may return a different type for the slot.
+	if slot, ok := wrapHTMLElement(o).(*HTMLSlotElement); ok {
+		return slot
+	}
+	return &HTMLSlotElement{BasicHTMLElement: &BasicHTMLElement{&BasicElement{&BasicNode{o}}}}
+}
+
+// AttachShadow attaches a shadow DOM tree to the element and returns
+// its shadow root.
+func (e *BasicElement) AttachShadow(init ShadowRootInit) ShadowRoot {
+	mode := init.Mode
+	if mode == "" {
+		mode = ShadowRootOpen
+	}
+	opts := map[string]interface{}{
+		"mode":           string(mode),
+		"delegatesFocus": init.DelegatesFocus,
+	}
+	if init.SlotAssignment != "" {
+		opts["slotAssignment"] = string(init.SlotAssignment)
+	}
+	return wrapShadowRoot(e.Call("attachShadow", opts))
+}
+
+// ShadowRoot returns the element's shadow root, or nil if it doesn't
+// have one or if it is closed.
+func (e *BasicElement) ShadowRoot() ShadowRoot {
+	return wrapShadowRoot(e.Get("shadowRoot"))
+}
+
 func (e *BasicElement) GetBoundingClientRect() *Rect {
 	obj := e.Call("getBoundingClientRect")
 	return &Rect{Value: obj}
 }
 
+// GetClientRects returns the rectangles of the element's CSS boxes,
+// such as one per line of an inline element.
+func (e *BasicElement) GetClientRects() []*Rect {
+	rects := e.Call("getClientRects")
+	out := make([]*Rect, rects.Length())
+	for i := range out {
+		out[i] = &Rect{Value: rects.Index(i)}
+	}
+	return out
+}
+
+func (e *BasicElement) ClientHeight() int { return e.Get("clientHeight").Int() }
+func (e *BasicElement) ClientLeft() int   { return e.Get("clientLeft").Int() }
+func (e *BasicElement) ClientTop() int    { return e.Get("clientTop").Int() }
+func (e *BasicElement) ClientWidth() int  { return e.Get("clientWidth").Int() }
+
+func (e *BasicElement) ScrollHeight() int                        { return e.Get("scrollHeight").Int() }
+func (e *BasicElement) ScrollWidth() int                         { return e.Get("scrollWidth").Int() }
+func (e *BasicElement) ScrollLeft() float64                      { return e.Get("scrollLeft").Float() }
+func (e *BasicElement) SetScrollLeft(v float64)                  { e.Set("scrollLeft", v) }
+func (e *BasicElement) ScrollTop() float64                       { return e.Get("scrollTop").Float() }
+func (e *BasicElement) SetScrollTop(v float64)                   { e.Set("scrollTop", v) }
+func (e *BasicElement) ScrollWithOptions(opts ScrollToOptions)   { e.Call("scroll", opts.toJS()) }
+func (e *BasicElement) ScrollByWithOptions(opts ScrollToOptions) { e.Call("scrollBy", opts.toJS()) }
+func (e *BasicElement) ScrollToWithOptions(opts ScrollToOptions) { e.Call("scrollTo", opts.toJS()) }
+
+// ScrollIntoView scrolls the element's ancestors so that the element
+// becomes visible.
+func (e *BasicElement) ScrollIntoView(opts ScrollIntoViewOptions) {
+	o := map[string]interface{}{}
+	if opts.Behavior != "" {
+		o["behavior"] = string(opts.Behavior)
+	}
+	if opts.Block != "" {
+		o["block"] = string(opts.Block)
+	}
+	if opts.Inline != "" {
+		o["inline"] = string(opts.Inline)
+	}
+	e.Call("scrollIntoView", o)
+}
+
+// CheckVisibility reports whether the element is visible, based on
+// the checks in opts. Elements that aren't rendered, such as those
+// with display: none, are never visible.
+func (e *BasicElement) CheckVisibility(opts CheckVisibilityOptions) bool {
+	return e.Call("checkVisibility", map[string]interface{}{
+		"opacityProperty":       opts.OpacityProperty,
+		"visibilityProperty":    opts.VisibilityProperty,
+		"contentVisibilityAuto": opts.ContentVisibilityAuto,
+		// The original names of the first two options.
+		"checkOpacity":       opts.OpacityProperty,
+		"checkVisibilityCSS": opts.VisibilityProperty,
+	}).Bool()
+}
+
 func (e *BasicElement) PreviousElementSibling() Element {
 	return wrapElement(e.Get("previousElementSibling"))
 }
@@ -1820,6 +3445,90 @@ func (e *BasicElement) NextElementSibling() Element {
 	return wrapElement(e.Get("nextElementSibling"))
 }
 
+func (e *BasicElement) Before(nodes ...interface{}) {
+	e.Call("before", nodesOrStrings(nodes)...)
+}
+
+func (e *BasicElement) After(nodes ...interface{}) {
+	e.Call("after", nodesOrStrings(nodes)...)
+}
+
+func (e *BasicElement) ReplaceWith(nodes ...interface{}) {
+	e.Call("replaceWith", nodesOrStrings(nodes)...)
+}
+
+func (e *BasicElement) Children() []Element {
+	return nodeListToElements(e.Get("children"))
+}
+
+func (e *BasicElement) ChildrenLive() *HTMLCollection {
+	return &HTMLCollection{e.Get("children")}
+}
+
+func (e *BasicElement) ChildElementCount() int {
+	return e.Get("childElementCount").Int()
+}
+
+func (e *BasicElement) FirstElementChild() Element {
+	return wrapElement(e.Get("firstElementChild"))
+}
+
+func (e *BasicElement) LastElementChild() Element {
+	return wrapElement(e.Get("lastElementChild"))
+}
+
+func (e *BasicElement) Append(nodes ...interface{}) {
+	e.Call("append", nodesOrStrings(nodes)...)
+}
+
+func (e *BasicElement) Prepend(nodes ...interface{}) {
+	e.Call("prepend", nodesOrStrings(nodes)...)
+}
+
+func (e *BasicElement) ReplaceChildren(nodes ...interface{}) {
+	e.Call("replaceChildren", nodesOrStrings(nodes)...)
+}
+
+func (e *BasicElement) MoveBefore(node Node, child Node) {
+	var o interface{}
+	if child != nil {
+		o = child.Underlying()
+	}
+	e.Call("moveBefore", node.Underlying(), o)
+}
+
+func (e *BasicElement) BeforeErr(nodes ...interface{}) error {
+	return callRecover(e.Value, "before", nodesOrStrings(nodes)...)
+}
+
+func (e *BasicElement) AfterErr(nodes ...interface{}) error {
+	return callRecover(e.Value, "after", nodesOrStrings(nodes)...)
+}
+
+func (e *BasicElement) ReplaceWithErr(nodes ...interface{}) error {
+	return callRecover(e.Value, "replaceWith", nodesOrStrings(nodes)...)
+}
+
+func (e *BasicElement) AppendErr(nodes ...interface{}) error {
+	return callRecover(e.Value, "append", nodesOrStrings(nodes)...)
+}
+
+func (e *BasicElement) PrependErr(nodes ...interface{}) error {
+	return callRecover(e.Value, "prepend", nodesOrStrings(nodes)...)
+}
+
+func (e *BasicElement) ReplaceChildrenErr(nodes ...interface{}) error {
+	return callRecover(e.Value, "replaceChildren", nodesOrStrings(nodes)...)
+}
+
+func (e *BasicElement) MoveBeforeErr(node Node, child Node) error {
+	var o interface{}
+	if child != nil {
+		o = child.Underlying()
+	}
+	return callRecover(e.Value, "moveBefore", node.Underlying(), o)
+}
+
 func (e *BasicElement) Class() *TokenList {
 	return &TokenList{dtl: e.Get("classList"), o: e.Value, sa: "className"}
 }
@@ -1854,6 +3563,107 @@ func (e *BasicElement) GetAttributeNS(ns string, name string) string {
 	return toString(e.Call("getAttributeNS", ns, name))
 }
 
+func (e *BasicElement) GetAttributeOK(name string) (string, bool) {
+	v := e.Call("getAttribute", name)
+	if v.IsNull() {
+		return "", false
+	}
+	return v.String(), true
+}
+
+func (e *BasicElement) GetAttributeNames() []string {
+	names := e.Call("getAttributeNames")
+	out := make([]string, names.Length())
+	for i := range out {
+		out[i] = names.Index(i).String()
+	}
+	return out
+}
+
+// InsertAdjacentElement inserts el at pos and returns it, or returns
+// nil if pos is outside of the element and the element has no parent.
+func (e *BasicElement) InsertAdjacentElement(pos AdjacentPosition, el Element) Element {
+	return wrapElement(e.Call("insertAdjacentElement", string(pos), el.Underlying()))
+}
+
+// InsertAdjacentHTML parses html and inserts the resulting nodes at
+// pos.
+func (e *BasicElement) InsertAdjacentHTML(pos AdjacentPosition, html string) {
+	e.Call("insertAdjacentHTML", string(pos), html)
+}
+
+// InsertAdjacentText inserts a text node at pos. Nothing is inserted
+// if pos is outside of the element and the element has no parent.
+func (e *BasicElement) InsertAdjacentText(pos AdjacentPosition, text string) {
+	e.Call("insertAdjacentText", string(pos), text)
+}
+
+// RequestFullscreen displays the element in fullscreen mode. It blocks
+// until the element is in fullscreen mode and returns an error if the
+// request was denied. Successful and failed requests also dispatch a
+// fullscreenchange or fullscreenerror event at the element, which
+// bubbles up to the document. Older browsers, which don't return a
+// promise, only report the outcome with these events, and
+// RequestFullscreen returns nil right away.
+//
+// The request is only granted in response to a user interaction, such
+// as a click. Because blocking functions must not be called directly
+// from an event listener, call RequestFullscreen in a new goroutine
+// started by the listener:
+//
+//	el.AddEventListener("click", false, func(dom.Event) {
+//		go func() {
+//			if err := el.RequestFullscreen(dom.FullscreenOptions{}); err != nil {
+//				// ...
+//			}
+//		}()
+//	})
+func (e *BasicElement) RequestFullscreen(opts FullscreenOptions) error {
+	o := map[string]interface{}{}
+	if opts.NavigationUI != "" {
+		o["navigationUI"] = opts.NavigationUI
+	}
+	p, err := callValueRecover(e.Value, "requestFullscreen", o)
+	if err != nil || p.Type() != js.TypeObject {
+		return err
+	}
+	_, err = await(p)
+	return err
+}
+
+// RequestPointerLock locks the pointer to the element and hides it, so
+// that mouse movements are only reported via the MovementX and
+// MovementY of MouseEvent. If unadjustedMovement is true, these report
+// raw movements, without mouse acceleration. Changes to the lock are
+// signalled by pointerlockchange and pointerlockerror events at the
+// document, and Document.PointerLockElement returns the locked element.
+//
+// Like RequestFullscreen, RequestPointerLock blocks until the lock has
+// been acquired and has to be called in response to a user
+// interaction. In browsers in which requesting the lock doesn't return
+// a promise, it returns immediately, and only the events report
+// failures.
+func (e *BasicElement) RequestPointerLock(unadjustedMovement bool) error {
+	var opts interface{}
+	if unadjustedMovement {
+		opts = map[string]interface{}{"unadjustedMovement": true}
+	}
+	p, err := callValueRecover(e.Value, "requestPointerLock", opts)
+	if err != nil || p.Type() != js.TypeObject {
+		return err
+	}
+	_, err = await(p)
+	return err
+}
+
+func (e *BasicElement) ToggleAttribute(name string) bool {
+	return e.Call("toggleAttribute", name).Bool()
+}
+
+func (e *BasicElement) ToggleAttributeForce(name string, force bool) bool {
+	return e.Call("toggleAttribute", name, force).Bool()
+}
+
 func (e *BasicElement) GetElementsByClassName(s string) []Element {
 	return nodeListToElements(e.Call("getElementsByClassName", s))
 }
@@ -1866,6 +3676,18 @@ func (e *BasicElement) GetElementsByTagNameNS(ns string, name string) []Element
 	return nodeListToElements(e.Call("getElementsByTagNameNS", ns, name))
 }
 
+func (e *BasicElement) GetElementsByClassNameLive(s string) *HTMLCollection {
+	return &HTMLCollection{e.Call("getElementsByClassName", s)}
+}
+
+func (e *BasicElement) GetElementsByTagNameLive(s string) *HTMLCollection {
+	return &HTMLCollection{e.Call("getElementsByTagName", s)}
+}
+
+func (e *BasicElement) GetElementsByTagNameNSLive(ns string, name string) *HTMLCollection {
+	return &HTMLCollection{e.Call("getElementsByTagNameNS", ns, name)}
+}
+
 func (e *BasicElement) HasAttribute(s string) bool {
 	return e.Call("hasAttribute", s).Bool()
 }
@@ -1911,7 +3733,25 @@ func (e *BasicElement) InnerHTML() string {
 }
 
 func (e *BasicElement) SetInnerHTML(s string) {
-	e.Set("innerHTML", s)
+	if err := e.SetInnerHTMLErr(s); err != nil {
+		panic(err)
+	}
+}
+
+// SetHTMLUnsafe is like SetInnerHTML, but also parses declarative
+// shadow roots. The HTML is not sanitized. Like ParseHTMLUnsafe, it
+// returns ErrNotSupported if the browser doesn't support it.
+func (e *BasicElement) SetHTMLUnsafe(html string) error {
+	return setHTMLUnsafe(e.Value, html)
+}
+
+// setHTMLUnsafe calls the setHTMLUnsafe method of the element or
+// shadow root o.
+func setHTMLUnsafe(o js.Value, html string) error {
+	if o.Get("setHTMLUnsafe").Type() != js.TypeFunction {
+		return ErrNotSupported
+	}
+	return callRecover(o, "setHTMLUnsafe", html)
 }
 
 func (e *BasicElement) OuterHTML() string {
@@ -1919,7 +3759,94 @@ func (e *BasicElement) OuterHTML() string {
 }
 
 func (e *BasicElement) SetOuterHTML(s string) {
-	e.Set("outerHTML", s)
+	if err := e.SetOuterHTMLErr(s); err != nil {
+		panic(err)
+	}
+}
+
+func (e *BasicElement) AttachShadowErr(init ShadowRootInit) (root ShadowRoot, err error) {
+	defer recoverError(&err)
+	return e.AttachShadow(init), nil
+}
+
+func (e *BasicElement) ClosestErr(s string) (Element, error) {
+	o, err := callValueRecover(e.Value, "closest", s)
+	if err != nil {
+		return nil, err
+	}
+	return wrapElement(o), nil
+}
+
+func (e *BasicElement) InsertAdjacentElementErr(pos AdjacentPosition, el Element) (Element, error) {
+	v, err := callValueRecover(e.Value, "insertAdjacentElement", string(pos), el.Underlying())
+	if err != nil {
+		return nil, err
+	}
+	return wrapElement(v), nil
+}
+
+func (e *BasicElement) InsertAdjacentHTMLErr(pos AdjacentPosition, html string) error {
+	return callRecover(e.Value, "insertAdjacentHTML", string(pos), html)
+}
+
+func (e *BasicElement) InsertAdjacentTextErr(pos AdjacentPosition, text string) error {
+	return callRecover(e.Value, "insertAdjacentText", string(pos), text)
+}
+
+func (e *BasicElement) MatchesErr(s string) (bool, error) {
+	o, err := callValueRecover(e.Value, "matches", s)
+	if err != nil {
+		return false, err
+	}
+	return o.Bool(), nil
+}
+
+func (e *BasicElement) QuerySelectorErr(s string) (Element, error) {
+	o, err := callValueRecover(e.Value, "querySelector", s)
+	if err != nil {
+		return nil, err
+	}
+	return wrapElement(o), nil
+}
+
+func (e *BasicElement) QuerySelectorAllErr(s string) ([]Element, error) {
+	o, err := callValueRecover(e.Value, "querySelectorAll", s)
+	if err != nil {
+		return nil, err
+	}
+	return nodeListToElements(o), nil
+}
+
+func (e *BasicElement) SetAttributeErr(name string, value string) error {
+	return callRecover(e.Value, "setAttribute", name, value)
+}
+
+func (e *BasicElement) SetAttributeNSErr(ns string, name string, value string) error {
+	return callRecover(e.Value, "setAttributeNS", ns, name, value)
+}
+
+func (e *BasicElement) SetInnerHTMLErr(s string) error {
+	return setRecover(e.Value, "innerHTML", s)
+}
+
+func (e *BasicElement) ToggleAttributeErr(name string) (bool, error) {
+	v, err := callValueRecover(e.Value, "toggleAttribute", name)
+	if err != nil {
+		return false, err
+	}
+	return v.Bool(), nil
+}
+
+func (e *BasicElement) ToggleAttributeForceErr(name string, force bool) (bool, error) {
+	v, err := callValueRecover(e.Value, "toggleAttribute", name, force)
+	if err != nil {
+		return false, err
+	}
+	return v.Bool(), nil
+}
+
+func (e *BasicElement) SetOuterHTMLErr(s string) error {
+	return setRecover(e.Value, "outerHTML", s)
 }
 
 type HTMLAnchorElement struct {
@@ -2192,6 +4119,12 @@ func (cg *CanvasGradient) AddColorStop(offset float64, color string) {
 	cg.Call("addColorStop", offset, color)
 }
 
+// AddColorStopErr is like AddColorStop, but returns an error instead
+// of panicking.
+func (cg *CanvasGradient) AddColorStopErr(offset float64, color string) error {
+	return callRecover(cg.Value, "addColorStop", offset, color)
+}
+
 // CanvasPattern represents an opaque object describing a pattern.
 // It is based on an image, a canvas or a video, created by the
 // CanvasRenderingContext2D.CreatePattern method.
@@ -2444,6 +4377,17 @@ func (ctx *CanvasRenderingContext2D) GetImageData(sx, sy, sw, sh int) *ImageData
 	return &ImageData{Value: ctx.Call("getImageData", sx, sy, sw, sh)}
 }
 
+// GetImageDataErr is like GetImageData, but returns ErrIndexSize if sw
+// or sh is zero and ErrSecurity if the canvas is tainted by
+// cross-origin data.
+func (ctx *CanvasRenderingContext2D) GetImageDataErr(sx, sy, sw, sh int) (*ImageData, error) {
+	v, err := callValueRecover(ctx.Value, "getImageData", sx, sy, sw, sh)
+	if err != nil {
+		return nil, err
+	}
+	return &ImageData{Value: v}, nil
+}
+
 func (ctx *CanvasRenderingContext2D) PutImageData(imageData *ImageData, dx, dy float64) {
 	ctx.Call("putImageData", imageData.Value, dx, dy)
 }
@@ -2592,7 +4536,7 @@ type HTMLIFrameElement struct {
 }
 
 func (e *HTMLIFrameElement) ContentDocument() Document { return wrapDocument(e.Get("contentDocument")) }
-func (e *HTMLIFrameElement) ContentWindow() Window     { return &window{e.Get("contentWindow")} }
+func (e *HTMLIFrameElement) ContentWindow() Window     { return wrapWindow(e.Get("contentWindow")) }
 func (e *HTMLIFrameElement) Height() string            { return e.Get("height").String() }
 func (e *HTMLIFrameElement) Name() string              { return e.Get("name").String() }
 func (e *HTMLIFrameElement) Seamless() bool            { return e.Get("seamless").Bool() }
@@ -2743,6 +4687,12 @@ func (e *HTMLInputElement) Select()                     { e.Call("select") }
 func (e *HTMLInputElement) SetSelectionRange(start, end int, direction string) {
 	e.Call("setSelectionRange", start, end, direction)
 }
+
+// SetSelectionRangeErr is like SetSelectionRange, but returns
+// ErrInvalidState if the input's type doesn't support selection.
+func (e *HTMLInputElement) SetSelectionRangeErr(start, end int, direction string) error {
+	return callRecover(e.Underlying(), "setSelectionRange", start, end, direction)
+}
 func (e *HTMLInputElement) StepDown(n int) error { return callRecover(e.Underlying(), "stepDown", n) }
 func (e *HTMLInputElement) StepUp(n int) error   { return callRecover(e.Underlying(), "stepUp", n) }
 
@@ -2911,7 +4861,7 @@ type HTMLObjectElement struct {
 
 func (e *HTMLObjectElement) CheckValidity() bool       { return e.Call("checkValidity").Bool() }
 func (e *HTMLObjectElement) ContentDocument() Document { return wrapDocument(e.Get("contentDocument")) }
-func (e *HTMLObjectElement) ContentWindow() Window     { return &window{e.Get("contentWindow")} }
+func (e *HTMLObjectElement) ContentWindow() Window     { return wrapWindow(e.Get("contentWindow")) }
 func (e *HTMLObjectElement) Data() string              { return e.Get("data").String() }
 func (e *HTMLObjectElement) Form() *HTMLFormElement    { return getForm(e.Value) }
 func (e *HTMLObjectElement) Height() string            { return e.Get("height").String() }
@@ -3102,6 +5052,44 @@ func (e *HTMLSelectElement) CheckValidity() bool { return e.Call("checkValidity"
 // there's already InsertBefore and RemoveChild which can be used
 // instead.
 
+// HTMLSlotElement represents a <slot> element, a placeholder inside a
+// shadow tree that is filled with nodes from the shadow host's light
+// DOM. It fires "slotchange" events when the nodes assigned to it
+// change.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/HTMLSlotElement.
+type HTMLSlotElement struct {
+	*BasicHTMLElement
+}
+
+func (e *HTMLSlotElement) Name() string { return e.Get("name").String() }
+
+func (e *HTMLSlotElement) SetName(v string) { e.Set("name", v) }
+
+// AssignedNodes returns the nodes assigned to the slot. If flatten is
+// true, slots assigned to the slot are replaced by their assigned
+// nodes, and if nothing is assigned, the slot's fallback content is
+// returned.
+func (e *HTMLSlotElement) AssignedNodes(flatten bool) []Node {
+	return nodeListToNodes(e.Call("assignedNodes", map[string]interface{}{"flatten": flatten}))
+}
+
+// AssignedElements is like AssignedNodes, but only returns elements.
+func (e *HTMLSlotElement) AssignedElements(flatten bool) []Element {
+	return nodeListToElements(e.Call("assignedElements", map[string]interface{}{"flatten": flatten}))
+}
+
+// Assign assigns nodes to the slot, replacing its previous
+// assignment. It only has an effect in shadow roots that use
+// SlotAssignmentManual.
+func (e *HTMLSlotElement) Assign(nodes ...Node) {
+	args := make([]interface{}, len(nodes))
+	for i, n := range nodes {
+		args[i] = n.Underlying()
+	}
+	e.Call("assign", args...)
+}
+
 type HTMLSourceElement struct {
 	*BasicHTMLElement
 }
@@ -3166,15 +5154,40 @@ func (e *HTMLTableRowElement) Cells() []*HTMLTableCellElement {
 	return out
 }
 
+func (e *HTMLTableRowElement) CellsLive() *HTMLCollection {
+	return &HTMLCollection{e.Get("cells")}
+}
+
 func (e *HTMLTableRowElement) InsertCell(index int) *HTMLTableCellElement {
 	return wrapHTMLElement(e.Call("insertCell", index)).(*HTMLTableCellElement)
 }
 
+// InsertCellErr is like InsertCell, but returns ErrIndexSize instead
+// of panicking if index is out of bounds.
+func (e *HTMLTableRowElement) InsertCellErr(index int) (*HTMLTableCellElement, error) {
+	o, err := callValueRecover(e.Value, "insertCell", index)
+	if err != nil {
+		return nil, err
+	}
+	// A registered wrapper may return a different type.
+	if el, ok := wrapHTMLElement(o).(*HTMLTableCellElement); ok {
+		return el, nil
+	}
+	return &HTMLTableCellElement{BasicHTMLElement: &BasicHTMLElement{&BasicElement{&BasicNode{o}}}}, nil
+}
+
+// DeleteCell deletes the cell at index. It panics if index is out of
+// bounds; use DeleteCellErr to handle that case.
 func (e *HTMLTableRowElement) DeleteCell(index int) {
-	// FIXME exception handling/check that index is in bounds
 	e.Call("deleteCell", index)
 }
 
+// DeleteCellErr is like DeleteCell, but returns ErrIndexSize instead
+// of panicking if index is out of bounds.
+func (e *HTMLTableRowElement) DeleteCellErr(index int) error {
+	return callRecover(e.Value, "deleteCell", index)
+}
+
 type HTMLTableSectionElement struct{ *BasicHTMLElement }
 
 func (e *HTMLTableSectionElement) Rows() []*HTMLTableRowElement {
@@ -3186,15 +5199,40 @@ func (e *HTMLTableSectionElement) Rows() []*HTMLTableRowElement {
 	return out
 }
 
+func (e *HTMLTableSectionElement) RowsLive() *HTMLCollection {
+	return &HTMLCollection{e.Get("rows")}
+}
+
+// DeleteRow deletes the row at index. It panics if index is out of
+// bounds; use DeleteRowErr to handle that case.
 func (e *HTMLTableSectionElement) DeleteRow(index int) {
-	// FIXME exception handling/check that index is in bounds
 	e.Call("deleteRow", index)
 }
 
+// DeleteRowErr is like DeleteRow, but returns ErrIndexSize instead of
+// panicking if index is out of bounds.
+func (e *HTMLTableSectionElement) DeleteRowErr(index int) error {
+	return callRecover(e.Value, "deleteRow", index)
+}
+
 func (e *HTMLTableSectionElement) InsertRow(index int) *HTMLTableRowElement {
 	return wrapHTMLElement(e.Call("insertRow", index)).(*HTMLTableRowElement)
 }
 
+// InsertRowErr is like InsertRow, but returns ErrIndexSize instead of
+// panicking if index is out of bounds.
+func (e *HTMLTableSectionElement) InsertRowErr(index int) (*HTMLTableRowElement, error) {
+	o, err := callValueRecover(e.Value, "insertRow", index)
+	if err != nil {
+		return nil, err
+	}
+	// A registered wrapper may return a different type.
+	if el, ok := wrapHTMLElement(o).(*HTMLTableRowElement); ok {
+		return el, nil
+	}
+	return &HTMLTableRowElement{BasicHTMLElement: &BasicHTMLElement{&BasicElement{&BasicNode{o}}}}, nil
+}
+
 type HTMLTemplateElement struct{ *BasicHTMLElement }
 
 func (e *HTMLTemplateElement) Content() DocumentFragment {
@@ -3258,6 +5296,12 @@ func (e *HTMLTextAreaElement) SetSelectionRange(start, end int, direction string
 	e.Call("setSelectionRange", start, end, direction)
 }
 
+// SetSelectionRangeErr is like SetSelectionRange, but returns any
+// exception as an error.
+func (e *HTMLTextAreaElement) SetSelectionRangeErr(start, end int, direction string) error {
+	return callRecover(e.Underlying(), "setSelectionRange", start, end, direction)
+}
+
 func (e *HTMLTextAreaElement) CheckValidity() bool { return e.Call("checkValidity").Bool() }
 func (e *HTMLTextAreaElement) Select()             { e.Call("select") }
 
@@ -3356,6 +5400,59 @@ type Text struct {
 	*BasicNode
 }
 
+// Comment is a comment node, such as <!-- comment -->.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/Comment.
+type Comment struct {
+	*BasicNode
+}
+
+// Data returns the text of the comment, without the delimiters.
+func (c *Comment) Data() string { return c.Get("data").String() }
+
+func (c *Comment) SetData(data string) { c.Set("data", data) }
+
+// CDATASection is a CDATA section in an XML document, such as
+// <![CDATA[ text ]]>. Like in JavaScript, it is a kind of Text.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/CDATASection.
+type CDATASection struct {
+	*Text
+}
+
+// ProcessingInstruction is a processing instruction, such as
+// <?xml-stylesheet href="style.css"?>.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/ProcessingInstruction.
+type ProcessingInstruction struct {
+	*BasicNode
+}
+
+// Target returns the application that the instruction is for, such as
+// xml-stylesheet.
+func (p *ProcessingInstruction) Target() string { return p.Get("target").String() }
+
+// Data returns the content of the instruction that follows the target.
+func (p *ProcessingInstruction) Data() string { return p.Get("data").String() }
+
+func (p *ProcessingInstruction) SetData(data string) { p.Set("data", data) }
+
+// Attr is an attribute of an element, as a node. Most code should use
+// the attribute methods of Element instead.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/Attr.
+type Attr struct {
+	*BasicNode
+}
+
+func (a *Attr) Name() string          { return a.Get("name").String() }
+func (a *Attr) LocalName() string     { return a.Get("localName").String() }
+func (a *Attr) NamespaceURI() string  { return toString(a.Get("namespaceURI")) }
+func (a *Attr) Prefix() string        { return toString(a.Get("prefix")) }
+func (a *Attr) Value() string         { return a.Get("value").String() }
+func (a *Attr) SetValue(v string)     { a.Set("value", v) }
+func (a *Attr) OwnerElement() Element { return wrapElement(a.Get("ownerElement")) }
+
 // DataTransfer holds the data that is being
 // dragged during a drag and drop operation.
 type DataTransfer struct{ js.Value }
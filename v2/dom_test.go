@@ -4,15 +4,976 @@
 package dom
 
 import (
+	"errors"
 	"image"
 	"image/draw"
+	"os"
+	"syscall/js"
+	"testing"
+	"time"
 )
 
 var _ Node = &BasicNode{}
 var _ HTMLElement = &BasicHTMLElement{}
 var _ Element = &BasicElement{}
 var _ Document = &document{}
+var _ DocumentFragment = &documentFragment{}
 var _ Window = &window{}
+var _ EventTarget = &BasicEventTarget{}
 var _ HTMLDocument = &htmlDocument{}
+var _ DocumentType = &documentType{}
+var _ DOMImplementation = &domImplementation{}
+var _ ShadowRoot = &shadowRoot{}
 var _ image.Image = &ImageData{}
 var _ draw.Image = &ImageData{}
+
+// stubConstructors installs empty constructors for all element and
+// event types that the JavaScript environment doesn't provide, so that
+// wrapping can be exercised outside of a browser.
+func stubConstructors() {
+	global := js.Global()
+	stub := func(name string) {
+		if global.Get(name).IsUndefined() {
+			global.Set(name, global.Get("Function").New())
+		}
+	}
+	stub("HTMLElement")
+	stub("Text")
+	for _, w := range htmlElementWrappers {
+		stub(w.name)
+	}
+	for _, w := range eventWrappers {
+		stub(w.name)
+	}
+	for _, w := range documentWrappers {
+		stub(w.name)
+	}
+}
+
+func TestMain(m *testing.M) {
+	stubConstructors()
+	os.Exit(m.Run())
+}
+
+func TestWrapHTMLElement(t *testing.T) {
+	div := js.Global().Get("HTMLDivElement").New()
+	if _, ok := wrapHTMLElement(div).(*HTMLDivElement); !ok {
+		t.Errorf("got %T, want *HTMLDivElement", wrapHTMLElement(div))
+	}
+	a := js.Global().Get("HTMLAnchorElement").New()
+	if el, ok := wrapHTMLElement(a).(*HTMLAnchorElement); !ok || !el.URLUtils.Value.Equal(a) {
+		t.Errorf("got %T, want *HTMLAnchorElement with URLUtils", wrapHTMLElement(a))
+	}
+	obj := js.Global().Get("Object").New()
+	if _, ok := wrapHTMLElement(obj).(*BasicHTMLElement); !ok {
+		t.Errorf("got %T, want *BasicHTMLElement", wrapHTMLElement(obj))
+	}
+}
+
+func TestWrapEvent(t *testing.T) {
+	ev := js.Global().Get("PointerEvent").New()
+	if _, ok := wrapEvent(ev).(*PointerEvent); !ok {
+		t.Errorf("got %T, want *PointerEvent", wrapEvent(ev))
+	}
+}
+
+func TestComposedPath(t *testing.T) {
+	defer installGlobal("ShadowRoot", "class {}")()
+	// A window, a document and a span in the shadow root of a div, with
+	// a dispatchEvent function that calls the listeners along the path
+	// like a browser does for composed events.
+	tree := js.Global().Get("Function").New(`
+		const node = (ctor, parent, props) => Object.assign(new ctor(), props, {
+			parent,
+			listeners: [],
+			addEventListener(type, fn) { this.listeners.push(fn); },
+		});
+		const win = node(Object, null, {});
+		win.window = win;
+		const doc = node(HTMLDocument, win, {nodeType: 9});
+		const body = node(HTMLBodyElement, doc, {nodeType: 1});
+		const host = node(HTMLDivElement, body, {nodeType: 1});
+		const root = node(ShadowRoot, host, {nodeType: 11, host});
+		const span = node(HTMLSpanElement, root, {nodeType: 1});
+		const dispatchEvent = (target) => {
+			const path = [];
+			for (let n = target; n; n = n.parent) path.push(n);
+			const ev = {type: "click", target, currentTarget: null, composedPath: () => path.slice()};
+			for (const n of path) {
+				ev.currentTarget = n;
+				n.listeners.forEach(fn => fn(ev));
+			}
+		};
+		return {win, doc, body, host, root, span, dispatchEvent};
+	`).Invoke()
+
+	var calls int
+	(&BasicEventTarget{tree.Get("root")}).AddEventListener("click", false, func(ev Event) {
+		calls++
+		if _, ok := ev.TargetAsEventTarget().(*HTMLSpanElement); !ok {
+			t.Errorf("got target %T, want *HTMLSpanElement", ev.TargetAsEventTarget())
+		}
+		if _, ok := ev.CurrentTargetAsEventTarget().(ShadowRoot); !ok {
+			t.Errorf("got current target %T, want ShadowRoot", ev.CurrentTargetAsEventTarget())
+		}
+		path := ev.ComposedPath()
+		want := []string{"span", "root", "host", "body", "doc", "win"}
+		if len(path) != len(want) {
+			t.Fatalf("got path of length %d, want %d", len(path), len(want))
+		}
+		for i, name := range want {
+			var v js.Value
+			switch x := path[i].(type) {
+			case Node:
+				v = x.Underlying()
+			case *window:
+				v = x.Value
+			}
+			if !v.Equal(tree.Get(name)) {
+				t.Errorf("path[%d] isn't %s", i, name)
+			}
+		}
+		_, ok0 := path[0].(*HTMLSpanElement)
+		_, ok1 := path[1].(ShadowRoot)
+		_, ok2 := path[2].(*HTMLDivElement)
+		_, ok3 := path[3].(*HTMLBodyElement)
+		_, ok4 := path[4].(HTMLDocument)
+		_, ok5 := path[5].(Window)
+		for i, ok := range []bool{ok0, ok1, ok2, ok3, ok4, ok5} {
+			if !ok {
+				t.Errorf("path[%d] has unexpected type %T", i, path[i])
+			}
+		}
+	})
+	(&BasicEventTarget{tree.Get("win")}).AddEventListener("click", false, func(ev Event) {
+		calls++
+		if _, ok := ev.CurrentTargetAsEventTarget().(Window); !ok {
+			t.Errorf("got current target %T, want Window", ev.CurrentTargetAsEventTarget())
+		}
+	})
+	tree.Call("dispatchEvent", tree.Get("span"))
+	if calls != 2 {
+		t.Errorf("got %d listener calls, want 2", calls)
+	}
+}
+
+// subclass returns a new JavaScript class extending base.
+func subclass(base js.Value) js.Value {
+	return js.Global().Get("Function").New("Base", "return class extends Base {}").Invoke(base)
+}
+
+func TestWrapHTMLElementSubclass(t *testing.T) {
+	button := subclass(subclass(js.Global().Get("HTMLButtonElement"))).New()
+	if _, ok := wrapHTMLElement(button).(*HTMLButtonElement); !ok {
+		t.Errorf("got %T, want *HTMLButtonElement", wrapHTMLElement(button))
+	}
+	ev := subclass(js.Global().Get("KeyboardEvent")).New()
+	if _, ok := wrapEvent(ev).(*KeyboardEvent); !ok {
+		t.Errorf("got %T, want *KeyboardEvent", wrapEvent(ev))
+	}
+}
+
+type testWidget struct{ *BasicHTMLElement }
+
+func TestRegisterElementWrapper(t *testing.T) {
+	ctor := subclass(js.Global().Get("HTMLButtonElement"))
+	widget := ctor.New()
+	// Resolve the constructor once before registering, to make sure
+	// that registering invalidates earlier lookups.
+	if _, ok := wrapHTMLElement(widget).(*HTMLButtonElement); !ok {
+		t.Fatalf("got %T, want *HTMLButtonElement", wrapHTMLElement(widget))
+	}
+	RegisterElementWrapper(ctor, func(o js.Value) HTMLElement {
+		return &testWidget{&BasicHTMLElement{&BasicElement{&BasicNode{o}}}}
+	})
+	if _, ok := wrapHTMLElement(widget).(*testWidget); !ok {
+		t.Errorf("got %T, want *testWidget", wrapHTMLElement(widget))
+	}
+	if _, ok := wrapHTMLElement(subclass(ctor).New()).(*testWidget); !ok {
+		t.Errorf("subclass of registered constructor not wrapped as *testWidget")
+	}
+	button := js.Global().Get("HTMLButtonElement").New()
+	if _, ok := wrapHTMLElement(button).(*HTMLButtonElement); !ok {
+		t.Errorf("got %T, want *HTMLButtonElement", wrapHTMLElement(button))
+	}
+
+	// Registering the constructor again replaces the wrapper in place.
+	n := len(registeredElementWrappers)
+	RegisterElementWrapper(ctor, func(o js.Value) HTMLElement {
+		return &BasicHTMLElement{&BasicElement{&BasicNode{o}}}
+	})
+	if len(registeredElementWrappers) != n {
+		t.Errorf("got %d registered wrappers, want %d", len(registeredElementWrappers), n)
+	}
+	if _, ok := wrapHTMLElement(widget).(*BasicHTMLElement); !ok {
+		t.Errorf("got %T, want *BasicHTMLElement", wrapHTMLElement(widget))
+	}
+
+	// A freed slot is reused by the next registration.
+	unregisterElementWrapper(ctor)
+	if _, ok := wrapHTMLElement(widget).(*HTMLButtonElement); !ok {
+		t.Errorf("got %T after unregistering, want *HTMLButtonElement", wrapHTMLElement(widget))
+	}
+	RegisterElementWrapper(subclass(ctor), func(o js.Value) HTMLElement { return nil })
+	if len(registeredElementWrappers) != n {
+		t.Errorf("got %d registered wrappers, want %d", len(registeredElementWrappers), n)
+	}
+}
+
+// wrapHTMLElementLinear mimics how elements used to be wrapped, by
+// comparing the constructor against every known global constructor.
+func wrapHTMLElementLinear(o js.Value) HTMLElement {
+	el := &BasicHTMLElement{&BasicElement{&BasicNode{o}}}
+	c := elementConstructor(o)
+	for _, w := range htmlElementWrappers {
+		if c.Equal(js.Global().Get(w.name)) {
+			return w.wrap(el)
+		}
+	}
+	return el
+}
+
+func BenchmarkWrapHTMLElement(b *testing.B) {
+	// HTMLVideoElement is near the end of the list, which is the worst
+	// case for a linear search.
+	video := js.Global().Get("HTMLVideoElement").New()
+	b.Run("table", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			wrapHTMLElement(video)
+		}
+	})
+	b.Run("linear", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			wrapHTMLElementLinear(video)
+		}
+	})
+
+	// Elements such as <section> and autonomous custom elements have no
+	// constructor of their own in the table, which also makes wrapping
+	// check whether they come from another realm.
+	global := js.Global()
+	doc := global.Get("document")
+	if doc.IsUndefined() {
+		doc = global.Get("Object").New()
+		global.Set("document", doc)
+		defer func() {
+			global.Delete("document")
+			mainDocument = js.Undefined()
+		}()
+	}
+	section := global.Get("HTMLElement").New()
+	section.Set("ownerDocument", doc)
+	b.Run("unmatched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			wrapHTMLElement(section)
+		}
+	})
+}
+
+func TestCustomElementDefine(t *testing.T) {
+	var ctor js.Value
+	define := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		ctor = args[1]
+		return nil
+	})
+	defer define.Release()
+	reg := &CustomElementRegistry{Value: js.Global().Get("Object").New()}
+	reg.Set("define", define)
+
+	var connected HTMLElement
+	err := reg.Define("test-widget", ElementDefinition{
+		ConnectedCallback:  func(el HTMLElement) { connected = el },
+		ObservedAttributes: []string{"label"},
+		Wrap: func(o js.Value) HTMLElement {
+			return &testWidget{&BasicHTMLElement{&BasicElement{&BasicNode{o}}}}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := ctor.New()
+	if !o.InstanceOf(js.Global().Get("HTMLElement")) {
+		t.Error("instance doesn't extend HTMLElement")
+	}
+	if got := ctor.Get("observedAttributes").Index(0).String(); got != "label" {
+		t.Errorf("got observed attribute %q, want %q", got, "label")
+	}
+	o.Call("connectedCallback")
+	if _, ok := connected.(*testWidget); !ok || !connected.Underlying().Equal(o) {
+		t.Errorf("connectedCallback got %T, want *testWidget wrapping the instance", connected)
+	}
+}
+
+func TestCustomElementDefineFailure(t *testing.T) {
+	global := js.Global()
+	reg := &CustomElementRegistry{Value: global.Get("Object").New()}
+	reg.Set("define", global.Get("Function").New("name", "ctor", `
+		this.ctor = ctor;
+		throw new DOMException("already defined", "NotSupportedError");
+	`))
+	err := reg.Define("test-taken", ElementDefinition{
+		ConnectedCallback: func(HTMLElement) {},
+		Wrap: func(o js.Value) HTMLElement {
+			return &testWidget{&BasicHTMLElement{&BasicElement{&BasicNode{o}}}}
+		},
+	})
+	if !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("got %v, want ErrNotSupported", err)
+	}
+	if i := htmlElementConstructors().lookup(reg.Value.Get("ctor")); i >= len(htmlElementWrappers) {
+		t.Error("the wrapper is still registered after the definition failed")
+	}
+
+	// Failing again reuses the slot freed by the first failure.
+	n := len(registeredElementWrappers)
+	reg.Define("test-taken", ElementDefinition{
+		Wrap: func(o js.Value) HTMLElement { return nil },
+	})
+	if len(registeredElementWrappers) != n {
+		t.Errorf("got %d registered wrappers, want %d", len(registeredElementWrappers), n)
+	}
+}
+
+func TestCustomElementDefineRealm(t *testing.T) {
+	// The registry of another window, such as an iframe's, extends
+	// that window's HTMLElement.
+	global := js.Global()
+	realm := global.Get("Object").New()
+	realm.Set("HTMLElement", global.Get("Function").New("return class HTMLElement {}").Invoke())
+	var ctor js.Value
+	define := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		ctor = args[1]
+		return nil
+	})
+	defer define.Release()
+	reg := &CustomElementRegistry{Value: global.Get("Object").New(), global: realm}
+	reg.Set("define", define)
+	if err := reg.Define("test-realm", ElementDefinition{}); err != nil {
+		t.Fatal(err)
+	}
+	if o := ctor.New(); !o.InstanceOf(realm.Get("HTMLElement")) || o.InstanceOf(global.Get("HTMLElement")) {
+		t.Error("instance doesn't extend the realm's HTMLElement")
+	}
+}
+
+func TestWrapForeignRealm(t *testing.T) {
+	global := js.Global()
+	// A fake realm, standing in for an iframe's window, with its own
+	// element constructors.
+	realm := global.Get("Object").New()
+	realm.Set("Map", global.Get("Map"))
+	realm.Set("WeakMap", global.Get("WeakMap"))
+	realm.Set("Object", global.Get("Object"))
+	realm.Set("HTMLDivElement", global.Get("Function").New())
+	realm.Set("HTMLDocument", global.Get("Function").New())
+	doc := realm.Get("HTMLDocument").New()
+	doc.Set("defaultView", realm)
+	doc.Set("ownerDocument", js.Null())
+	doc.Set("nodeType", 9)
+
+	for i := 0; i < 2; i++ {
+		div := realm.Get("HTMLDivElement").New()
+		div.Set("ownerDocument", doc)
+		if _, ok := wrapHTMLElement(div).(*HTMLDivElement); !ok {
+			t.Errorf("got %T, want *HTMLDivElement", wrapHTMLElement(div))
+		}
+		if _, ok := (&BasicNode{div}).OwnerDocument().(HTMLDocument); !ok {
+			t.Errorf("got owner document %T, want HTMLDocument", (&BasicNode{div}).OwnerDocument())
+		}
+	}
+}
+
+func TestHTMLCollection(t *testing.T) {
+	global := js.Global()
+	arr := global.Get("Array").New(global.Get("HTMLDivElement").New(), global.Get("HTMLSpanElement").New())
+	c := &HTMLCollection{arr}
+	if c.Len() != 2 {
+		t.Fatalf("got length %d, want 2", c.Len())
+	}
+	if _, ok := c.Item(1).(*HTMLSpanElement); !ok {
+		t.Errorf("got %T, want *HTMLSpanElement", c.Item(1))
+	}
+	if el := c.Item(2); el != nil {
+		t.Errorf("got %T for out of range index, want nil", el)
+	}
+	// The collection is live: changes to the underlying object are
+	// visible without creating a new wrapper.
+	arr.Call("pop")
+	if c.Len() != 1 || len(c.Slice()) != 1 {
+		t.Errorf("got length %d, want 1", c.Len())
+	}
+}
+
+func TestDatasetNames(t *testing.T) {
+	tests := []struct {
+		attr string
+		key  string
+	}{
+		{"data-foo", "foo"},
+		{"data-foo-bar", "fooBar"},
+		{"data-foo-bar-baz", "fooBarBaz"},
+		{"data-foo--bar", "foo-Bar"},
+		{"data-foo-1", "foo-1"},
+		{"data-foo-", "foo-"},
+		{"data-", ""},
+	}
+	for _, tt := range tests {
+		if key, ok := DatasetKey(tt.attr); !ok || key != tt.key {
+			t.Errorf("DatasetKey(%q) = %q, %t, want %q", tt.attr, key, ok, tt.key)
+		}
+		if attr, ok := DatasetAttributeName(tt.key); !ok || attr != tt.attr {
+			t.Errorf("DatasetAttributeName(%q) = %q, %t, want %q", tt.key, attr, ok, tt.attr)
+		}
+	}
+	for _, attr := range []string{"foo", "data-fooBar", "dat-foo"} {
+		if _, ok := DatasetKey(attr); ok {
+			t.Errorf("DatasetKey(%q) succeeded, want failure", attr)
+		}
+	}
+	if _, ok := DatasetAttributeName("foo-bar"); ok {
+		t.Errorf("DatasetAttributeName(%q) succeeded, want failure", "foo-bar")
+	}
+}
+
+func TestDOMStringMapSet(t *testing.T) {
+	// Like a real DOMStringMap, the fake rejects keys containing a
+	// hyphen followed by a lowercase letter.
+	m := &DOMStringMap{js.Global().Get("Function").New(`
+		return new Proxy({}, {
+			set(target, key, value) {
+				if (/-[a-z]/.test(key)) throw new DOMException("invalid key", "SyntaxError");
+				target[key] = String(value);
+				return true;
+			},
+		});
+	`).Invoke()}
+	if err := m.SetErr("fooBar", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := m.Get("fooBar"); !ok || v != "1" {
+		t.Errorf("got (%q, %t), want (1, true)", v, ok)
+	}
+	if err := m.SetErr("foo-bar", "1"); !errors.Is(err, ErrSyntax) {
+		t.Errorf("got %v, want ErrSyntax", err)
+	}
+	for _, key := range []string{"toString", "constructor", "missing"} {
+		if v, ok := m.Get(key); ok || m.Has(key) {
+			t.Errorf("got (%q, %t) for %s, want no key", v, ok, key)
+		}
+	}
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrSyntax) {
+			t.Errorf("Set panicked with %v, want ErrSyntax", err)
+		}
+	}()
+	m.Set("foo-bar", "1")
+}
+
+func TestNodesOrStrings(t *testing.T) {
+	n := &BasicNode{js.Global().Get("Object").New()}
+	args := nodesOrStrings([]interface{}{"text", n})
+	if args[0] != "text" || !args[1].(js.Value).Equal(n.Value) {
+		t.Errorf("got %v, want the string and the underlying node", args)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("nodesOrStrings didn't panic on an int")
+		}
+	}()
+	nodesOrStrings([]interface{}{42})
+}
+
+func TestDOMException(t *testing.T) {
+	obj := js.Global().Get("Object").New()
+	throw := js.Global().Get("Function").New("throw new DOMException('bad selector', 'SyntaxError')")
+	obj.Set("querySelector", throw)
+	_, err := (&BasicElement{&BasicNode{obj}}).QuerySelectorErr("[")
+	if !errors.Is(err, ErrSyntax) {
+		t.Fatalf("got %v, want ErrSyntax", err)
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Errorf("%v matches ErrNotFound", err)
+	}
+	ex := err.(*DOMException)
+	if ex.Code != 12 || ex.Message != "bad selector" {
+		t.Errorf("got code %d and message %q, want 12 and %q", ex.Code, ex.Message, "bad selector")
+	}
+
+	obj.Set("removeChild", js.Global().Get("Function").New("throw new TypeError('not a node')"))
+	err = (&BasicNode{obj}).RemoveChildErr(&BasicNode{obj})
+	if _, ok := err.(js.Error); !ok {
+		t.Errorf("got %T, want js.Error", err)
+	}
+
+	// Callers of StepUp used to get a js.Error.
+	obj.Set("stepUp", throw)
+	err = (&HTMLInputElement{&BasicHTMLElement{&BasicElement{&BasicNode{obj}}}}).StepUp(1)
+	var jsErr js.Error
+	if !errors.As(err, &jsErr) || !jsErr.Value.Equal(err.(*DOMException).Value) {
+		t.Errorf("%v doesn't unwrap to its js.Error", err)
+	}
+}
+
+// throwingAccessor defines the property p of o with a getter and a
+// setter that throw a DOMException called name.
+func throwingAccessor(o js.Value, p, name string) {
+	throw := js.Global().Get("Function").New("throw new DOMException('denied', '" + name + "')")
+	js.Global().Get("Object").Call("defineProperty", o, p, map[string]interface{}{
+		"get":          throw,
+		"set":          throw,
+		"configurable": true,
+	})
+}
+
+func TestAccessorExceptions(t *testing.T) {
+	obj := js.Global().Get("Object").New()
+	throwingAccessor(obj, "innerHTML", "SyntaxError")
+	el := &BasicElement{&BasicNode{obj}}
+	if err := el.SetInnerHTMLErr("<"); !errors.Is(err, ErrSyntax) {
+		t.Errorf("got %v, want ErrSyntax", err)
+	}
+	func() {
+		defer func() {
+			if err, _ := recover().(error); !errors.Is(err, ErrSyntax) {
+				t.Errorf("SetInnerHTML panicked with %v, want ErrSyntax", err)
+			}
+		}()
+		el.SetInnerHTML("<")
+	}()
+	if _, err := getRecover(obj, "innerHTML"); !errors.Is(err, ErrSyntax) {
+		t.Errorf("got %v, want ErrSyntax", err)
+	}
+
+	obj.Set("value", 1)
+	if err := setRecover(obj, "value", 2); err != nil || obj.Get("value").Int() != 2 {
+		t.Errorf("got (%v, %v), want (<nil>, 2)", err, obj.Get("value"))
+	}
+	if v, err := getRecover(obj, "value"); err != nil || v.Int() != 2 {
+		t.Errorf("got (%v, %v), want (2, <nil>)", v, err)
+	}
+}
+
+func TestShadowRootWrapping(t *testing.T) {
+	global := js.Global()
+	obj := global.Get("Object").New()
+	el := &BasicElement{&BasicNode{obj}}
+	if el.ShadowRoot() != nil || el.AssignedSlot() != nil {
+		t.Error("got a shadow root or slot for an element without them")
+	}
+
+	// Neither object has a constructor known to the package.
+	root := global.Get("Object").New()
+	obj.Set("attachShadow", global.Get("Function").New("root", "return () => root").Invoke(root))
+	if r := el.AttachShadow(ShadowRootInit{}); r == nil || !r.Underlying().Equal(root) {
+		t.Errorf("got %v, want the shadow root", r)
+	}
+	obj.Set("shadowRoot", root)
+	if r := el.ShadowRoot(); r == nil || !r.Underlying().Equal(root) {
+		t.Errorf("got %v, want the shadow root", r)
+	}
+	slot := global.Get("Object").New()
+	obj.Set("assignedSlot", slot)
+	if s := el.AssignedSlot(); s == nil || !s.Underlying().Equal(slot) {
+		t.Errorf("got %v, want the slot", s)
+	}
+
+	obj.Set("attachShadow", global.Get("Function").New("throw new DOMException('', 'NotSupportedError')"))
+	if _, err := el.AttachShadowErr(ShadowRootInit{}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("got %v, want ErrNotSupported", err)
+	}
+}
+
+func TestInsertCellRowWrapping(t *testing.T) {
+	global := js.Global()
+	// The new cell and row have no constructor known to the package, as
+	// is the case for elements from other realms.
+	o := global.Get("Function").New(`return {
+		insertCell: () => ({nodeType: 1}),
+		insertRow: () => ({nodeType: 1}),
+	}`).Invoke()
+	el := &BasicHTMLElement{&BasicElement{&BasicNode{o}}}
+	if cell, err := (&HTMLTableRowElement{el}).InsertCellErr(0); err != nil || cell == nil {
+		t.Errorf("got (%v, %v), want a cell", cell, err)
+	}
+	if row, err := (&HTMLTableSectionElement{el}).InsertRowErr(0); err != nil || row == nil {
+		t.Errorf("got (%v, %v), want a row", row, err)
+	}
+}
+
+// fakeAttributeElement returns an element whose attribute methods are
+// backed by a Map, like those of a real element.
+func fakeAttributeElement() *BasicElement {
+	return &BasicElement{&BasicNode{js.Global().Get("Function").New(`
+		const attrs = new Map();
+		return {
+			getAttribute(name) { return attrs.has(name) ? attrs.get(name) : null; },
+			hasAttribute(name) { return attrs.has(name); },
+			getAttributeNames() { return [...attrs.keys()]; },
+			toggleAttribute(name, force) {
+				if (/\s/.test(name)) throw new DOMException("bad name", "InvalidCharacterError");
+				const add = force === undefined ? !attrs.has(name) : force;
+				if (add) { if (!attrs.has(name)) attrs.set(name, ""); } else attrs.delete(name);
+				return add;
+			},
+		};
+	`).Invoke()}}
+}
+
+func TestToggleAttribute(t *testing.T) {
+	el := fakeAttributeElement()
+	if !el.ToggleAttribute("hidden") {
+		t.Error("toggling a missing attribute reported false")
+	}
+	if v, ok := el.GetAttributeOK("hidden"); !ok || v != "" {
+		t.Errorf("got (%q, %t), want the empty attribute", v, ok)
+	}
+	if _, ok := el.GetAttributeOK("title"); ok {
+		t.Error("got a missing attribute")
+	}
+	if !el.ToggleAttributeForce("hidden", true) || !el.HasAttribute("hidden") {
+		t.Error("forcing an existing attribute removed it")
+	}
+	if el.ToggleAttribute("hidden") || el.HasAttribute("hidden") {
+		t.Error("toggling an existing attribute didn't remove it")
+	}
+	if el.ToggleAttributeForce("hidden", false) {
+		t.Error("forcing removal reported true")
+	}
+	el.ToggleAttribute("a")
+	el.ToggleAttributeForce("b", true)
+	if got := el.GetAttributeNames(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("got names %q, want [a b]", got)
+	}
+	if _, err := el.ToggleAttributeForceErr("a b", true); !errors.Is(err, ErrInvalidCharacter) {
+		t.Errorf("got %v, want ErrInvalidCharacter", err)
+	}
+	if ok, err := el.ToggleAttributeErr("c"); !ok || err != nil {
+		t.Errorf("got (%t, %v), want (true, <nil>)", ok, err)
+	}
+}
+
+func TestInsertAdjacent(t *testing.T) {
+	// The fake records its calls and, like a real element, rejects
+	// unknown positions.
+	obj := js.Global().Get("Function").New(`
+		const check = pos => {
+			if (!["beforebegin", "afterbegin", "beforeend", "afterend"].includes(pos))
+				throw new DOMException("bad position", "SyntaxError");
+		};
+		const el = {calls: []};
+		el.insertAdjacentElement = (pos, other) => { check(pos); el.calls.push(pos); return other; };
+		el.insertAdjacentHTML = (pos, html) => { check(pos); el.calls.push(pos + ":" + html); };
+		el.insertAdjacentText = (pos, text) => { check(pos); el.calls.push(pos + ":" + text); };
+		return el;
+	`).Invoke()
+	el := &BasicElement{&BasicNode{obj}}
+	other := &BasicElement{&BasicNode{js.Global().Get("Object").New()}}
+
+	if got := el.InsertAdjacentElement(AdjacentAfterEnd, other); got == nil || !got.Underlying().Equal(other.Value) {
+		t.Errorf("got %v, want the inserted element", got)
+	}
+	el.InsertAdjacentHTML(AdjacentAfterBegin, "<b>")
+	el.InsertAdjacentText(AdjacentBeforeEnd, "x")
+	want := []string{"afterend", "afterbegin:<b>", "beforeend:x"}
+	calls := obj.Get("calls")
+	for i, w := range want {
+		if got := calls.Index(i).String(); got != w {
+			t.Errorf("call %d: got %q, want %q", i, got, w)
+		}
+	}
+
+	if _, err := el.InsertAdjacentElementErr("middle", other); !errors.Is(err, ErrSyntax) {
+		t.Errorf("InsertAdjacentElementErr: got %v, want ErrSyntax", err)
+	}
+	if err := el.InsertAdjacentHTMLErr("middle", ""); !errors.Is(err, ErrSyntax) {
+		t.Errorf("InsertAdjacentHTMLErr: got %v, want ErrSyntax", err)
+	}
+	if err := el.InsertAdjacentTextErr("middle", ""); !errors.Is(err, ErrSyntax) {
+		t.Errorf("InsertAdjacentTextErr: got %v, want ErrSyntax", err)
+	}
+	if err := el.InsertAdjacentTextErr(AdjacentBeforeBegin, "y"); err != nil {
+		t.Error(err)
+	}
+}
+
+// installGlobal sets the global name to the class defined by src until
+// the returned function is called.
+func installGlobal(name, src string) (restore func()) {
+	global := js.Global()
+	old := global.Get(name)
+	global.Set(name, global.Get("Function").New("return "+src).Invoke())
+	return func() { global.Set(name, old) }
+}
+
+func TestDOMParser(t *testing.T) {
+	defer installGlobal("DOMParser", `class {
+		parseFromString(s, type) {
+			// Like browsers, return a Document for HTML and an
+			// XMLDocument for XML.
+			return Object.assign(new (type === "text/html" ? Document : XMLDocument)(), {
+				nodeType: 9,
+				contentType: type,
+				source: s,
+				doctype: type === "text/html" ? {nodeType: 10, name: "html", publicId: "", systemId: ""} : null,
+			});
+		}
+	}`)()
+	p := NewDOMParser()
+	doc := p.ParseFromString("<!DOCTYPE html><p>hi", "text/html")
+	if _, ok := doc.(HTMLDocument); !ok {
+		t.Errorf("got %T for text/html, want an HTMLDocument", doc)
+	}
+	if got := doc.Underlying().Get("source").String(); got != "<!DOCTYPE html><p>hi" {
+		t.Errorf("parsed %q", got)
+	}
+	dt := doc.Doctype()
+	if dt == nil || dt.Name() != "html" || dt.PublicID() != "" || dt.SystemID() != "" {
+		t.Errorf("got doctype %v, want html", dt)
+	}
+
+	doc = p.ParseFromString("<root/>", "application/xml")
+	if _, ok := doc.(HTMLDocument); ok {
+		t.Error("got an HTMLDocument for application/xml")
+	}
+	if doc.Doctype() != nil {
+		t.Error("got a doctype for a document without one")
+	}
+}
+
+func TestXMLSerializer(t *testing.T) {
+	defer installGlobal("XMLSerializer", `class {
+		serializeToString(n) {
+			if (typeof n.nodeName !== "string") throw new TypeError("not a node");
+			return "<" + n.nodeName + "/>";
+		}
+	}`)()
+	s := NewXMLSerializer()
+	n := js.Global().Get("Object").New()
+	n.Set("nodeName", "root")
+	if got, err := s.SerializeToString(&BasicNode{n}); err != nil || got != "<root/>" {
+		t.Errorf("got (%q, %v), want <root/>", got, err)
+	}
+	if _, err := s.SerializeToString(&BasicNode{js.Global().Get("Object").New()}); err == nil {
+		t.Error("serializing a non-node succeeded")
+	}
+}
+
+func TestSetHTMLUnsafe(t *testing.T) {
+	obj := js.Global().Get("Object").New()
+	el := &BasicElement{&BasicNode{obj}}
+	if err := el.SetHTMLUnsafe("<p>"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("got %v, want ErrNotSupported", err)
+	}
+	root := &shadowRoot{&documentFragment{&BasicNode{obj}}}
+	if err := root.SetHTMLUnsafe("<p>"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("got %v, want ErrNotSupported", err)
+	}
+	obj.Set("setHTMLUnsafe", js.Global().Get("Function").New("html", "this.html = html"))
+	if err := el.SetHTMLUnsafe("<p>"); err != nil || obj.Get("html").String() != "<p>" {
+		t.Errorf("got %v, want the HTML to be set", err)
+	}
+}
+
+// recordCalls sets each method of o to a function that stores its
+// first argument as o.calls[method].
+func recordCalls(o js.Value, methods ...string) {
+	calls := js.Global().Get("Object").New()
+	o.Set("calls", calls)
+	for _, m := range methods {
+		o.Set(m, js.Global().Get("Function").New("calls", "name", "return arg => { calls[name] = arg; return true; }").Invoke(calls, m))
+	}
+}
+
+func TestScrollOptions(t *testing.T) {
+	obj := js.Global().Get("Object").New()
+	recordCalls(obj, "scroll", "scrollBy", "scrollTo", "scrollIntoView", "checkVisibility")
+	el := &BasicElement{&BasicNode{obj}}
+	w := &window{obj}
+	calls := obj.Get("calls")
+	// keys returns the sorted property names of the options passed to
+	// method, as js.ValueOf sets the properties of a map in random order.
+	keys := func(method string) string {
+		return js.Global().Get("Object").Call("keys", calls.Get(method)).Call("sort").Call("join", ",").String()
+	}
+
+	top := 0.0
+	el.ScrollToWithOptions(ScrollToOptions{Top: &top})
+	if got := keys("scrollTo"); got != "top" {
+		t.Errorf("scrollTo got %q, want only top", got)
+	}
+	dx := 10.0
+	el.ScrollByWithOptions(ScrollToOptions{Left: &dx, Behavior: ScrollSmooth})
+	if got := keys("scrollBy"); got != "behavior,left" {
+		t.Errorf("scrollBy got %q, want left and behavior", got)
+	}
+	if got := calls.Get("scrollBy").Get("left").Float(); got != 10 {
+		t.Errorf("scrollBy got left %v, want 10", got)
+	}
+	w.ScrollWithOptions(ScrollToOptions{Left: &dx, Top: &top})
+	if got := keys("scroll"); got != "left,top" {
+		t.Errorf("scroll got %q, want left and top", got)
+	}
+
+	el.ScrollIntoView(ScrollIntoViewOptions{Block: ScrollCenter})
+	if got := keys("scrollIntoView"); got != "block" || calls.Get("scrollIntoView").Get("block").String() != "center" {
+		t.Errorf("scrollIntoView got %q", got)
+	}
+	if !el.CheckVisibility(CheckVisibilityOptions{OpacityProperty: true}) {
+		t.Error("CheckVisibility returned false")
+	}
+	if o := calls.Get("checkVisibility"); !o.Get("opacityProperty").Bool() || !o.Get("checkOpacity").Bool() {
+		t.Errorf("checkVisibility didn't get the opacity option under both names")
+	}
+}
+
+func TestNodeFilter(t *testing.T) {
+	text := js.Global().Get("Text").New()
+	fn, v := jsFilter(func(n Node) FilterResult {
+		if _, ok := n.(*Text); ok {
+			return FilterAccept
+		}
+		return FilterSkip
+	})
+	defer fn.Release()
+	if got := v.(js.Value).Invoke(text).Int(); got != int(FilterAccept) {
+		t.Errorf("got %d for a text node, want FilterAccept", got)
+	}
+	if _, v := jsFilter(nil); v != nil {
+		t.Errorf("got %v for a nil filter, want nil", v)
+	}
+}
+
+func TestURL(t *testing.T) {
+	u, err := NewURLWithBase("../c?b=2&a=1&b=3#frag", "https://example.com/a/b/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := u.String(), "https://example.com/a/c?b=2&a=1&b=3#frag"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	gu, err := u.URL()
+	if err != nil || gu.Path != "/a/c" || gu.Fragment != "frag" {
+		t.Errorf("got (%v, %v), want path /a/c", gu, err)
+	}
+
+	p := u.SearchParams()
+	if got := p.GetAll("b"); len(got) != 2 || got[0] != "2" || got[1] != "3" {
+		t.Errorf("got %q, want [2 3]", got)
+	}
+	if _, ok := p.Get("c"); ok {
+		t.Error("got value for missing parameter")
+	}
+	p.Sort()
+	p.Append("c", "x y")
+	if got, want := u.Search(), "?a=1&b=2&b=3&c=x+y"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if v := p.Values(); v.Get("c") != "x y" || len(v["b"]) != 2 || p.Size() != 4 {
+		t.Errorf("got %v", v)
+	}
+
+	if _, err := NewURL("not a url"); err == nil {
+		t.Error("parsing an invalid URL succeeded")
+	}
+}
+
+func TestComment(t *testing.T) {
+	o := js.Global().Get("Object").New()
+	o.Set("nodeType", 8)
+	o.Set("data", " note ")
+	c, ok := wrapNode(o).(*Comment)
+	if !ok {
+		t.Fatalf("got %T, want *Comment", wrapNode(o))
+	}
+	if got := c.Data(); got != " note " {
+		t.Errorf("got %q, want %q", got, " note ")
+	}
+	c.SetData("changed")
+	if got := o.Get("data").String(); got != "changed" {
+		t.Errorf("got %q after SetData, want changed", got)
+	}
+}
+
+func TestWrapNonElementNodes(t *testing.T) {
+	pi := js.Global().Get("Object").New()
+	pi.Set("nodeType", 7)
+	pi.Set("target", "xml-stylesheet")
+	pi.Set("data", `href="style.css"`)
+	if p, ok := wrapNode(pi).(*ProcessingInstruction); !ok || p.Target() != "xml-stylesheet" || p.Data() != `href="style.css"` {
+		t.Errorf("got %T, want *ProcessingInstruction", wrapNode(pi))
+	}
+	cdata := js.Global().Get("Object").New()
+	cdata.Set("nodeType", 4)
+	if _, ok := wrapNode(cdata).(*CDATASection); !ok {
+		t.Errorf("got %T, want *CDATASection", wrapNode(cdata))
+	}
+}
+
+func TestLastModified(t *testing.T) {
+	o := js.Global().Get("Object").New()
+	o.Set("lastModified", "03/04/2021 10:20:30")
+	d := &htmlDocument{&document{&BasicNode{o}}}
+	if got, want := d.LastModified(), time.Date(2021, 3, 4, 10, 20, 30, 0, time.Local); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// Other formats are left to Date.
+	o.Set("lastModified", "2021-03-04T10:20:30")
+	if got, want := d.LastModified(), time.Date(2021, 3, 4, 10, 20, 30, 0, time.Local); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	o.Set("lastModified", "yesterday")
+	if got := d.LastModified(); !got.IsZero() {
+		t.Errorf("got %v, want the zero time", got)
+	}
+}
+
+func TestStartViewTransition(t *testing.T) {
+	d := &htmlDocument{&document{&BasicNode{js.Global().Get("Object").New()}}}
+	updated := false
+	vt := d.StartViewTransition(func() { updated = true })
+	if !updated {
+		t.Error("update wasn't called")
+	}
+	if err := vt.Finished(); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+	if err := vt.Ready(); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+	vt.SkipTransition()
+}
+
+func TestRequestFullscreen(t *testing.T) {
+	o := js.Global().Get("Object").New()
+	el := &BasicElement{&BasicNode{o}}
+
+	o.Set("requestFullscreen", js.Global().Get("Function").New("return Promise.resolve()"))
+	if err := el.RequestFullscreen(FullscreenOptions{NavigationUI: "hide"}); err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+	o.Set("requestFullscreen", js.Global().Get("Function").New(
+		`return Promise.reject(new DOMException("denied", "NotAllowedError"))`))
+	if err := el.RequestFullscreen(FullscreenOptions{}); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("got error %v, want ErrNotAllowed", err)
+	}
+	// Older browsers don't return a promise.
+	o.Set("requestFullscreen", js.Global().Get("Function").New())
+	if err := el.RequestFullscreen(FullscreenOptions{}); err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+	o.Set("requestPointerLock", js.Global().Get("Function").New())
+	if err := el.RequestPointerLock(true); err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+
+	o.Set("exitFullscreen", js.Global().Get("Function").New())
+	d := &htmlDocument{&document{&BasicNode{o}}}
+	if err := d.ExitFullscreen(); err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+	o.Set("exitFullscreen", js.Global().Get("Function").New(
+		`return Promise.reject(new TypeError("not in fullscreen"))`))
+	if err := d.ExitFullscreen(); err == nil {
+		t.Error("got no error for a rejected exit")
+	}
+}
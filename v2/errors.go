@@ -0,0 +1,158 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"syscall/js"
+)
+
+// DOMException is an error reported by a DOM API. Errors returned by
+// this package that originate from a DOMException have this type.
+// Use errors.Is with one of the Err variables to check for a specific
+// kind of exception:
+//
+//	if err := parent.AppendChildErr(child); errors.Is(err, dom.ErrHierarchyRequest) {
+//		// ...
+//	}
+//
+// Older versions of this package returned such exceptions as js.Error.
+// A DOMException unwraps to the js.Error of its Value, so code using
+// errors.As with a *js.Error keeps working.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/DOMException.
+type DOMException struct {
+	// Name identifies the kind of exception, such as "NotFoundError".
+	Name string
+	// Code is the legacy numeric code of the exception, or 0 for
+	// exceptions that don't have one.
+	Code int
+	// Message describes the error in more detail.
+	Message string
+	// Value is the underlying JavaScript exception. It is undefined
+	// for the Err variables.
+	Value js.Value
+}
+
+func (e *DOMException) Error() string {
+	if e.Message == "" {
+		return e.Name
+	}
+	return e.Name + ": " + e.Message
+}
+
+// Unwrap returns the underlying JavaScript exception as a js.Error, or
+// nil for the Err variables.
+func (e *DOMException) Unwrap() error {
+	if e.Value.IsUndefined() {
+		return nil
+	}
+	return js.Error{Value: e.Value}
+}
+
+// Is reports whether target is a DOMException with the same name as
+// e.
+func (e *DOMException) Is(target error) bool {
+	t, ok := target.(*DOMException)
+	return ok && t.Name == e.Name
+}
+
+// Errors that can be compared against with errors.Is.
+var (
+	ErrIndexSize             = &DOMException{Name: "IndexSizeError", Code: 1}
+	ErrHierarchyRequest      = &DOMException{Name: "HierarchyRequestError", Code: 3}
+	ErrWrongDocument         = &DOMException{Name: "WrongDocumentError", Code: 4}
+	ErrInvalidCharacter      = &DOMException{Name: "InvalidCharacterError", Code: 5}
+	ErrNoModificationAllowed = &DOMException{Name: "NoModificationAllowedError", Code: 7}
+	ErrNotFound              = &DOMException{Name: "NotFoundError", Code: 8}
+	ErrNotSupported          = &DOMException{Name: "NotSupportedError", Code: 9}
+	ErrInUseAttribute        = &DOMException{Name: "InUseAttributeError", Code: 10}
+	ErrInvalidState          = &DOMException{Name: "InvalidStateError", Code: 11}
+	ErrSyntax                = &DOMException{Name: "SyntaxError", Code: 12}
+	ErrInvalidModification   = &DOMException{Name: "InvalidModificationError", Code: 13}
+	ErrNamespace             = &DOMException{Name: "NamespaceError", Code: 14}
+	ErrInvalidAccess         = &DOMException{Name: "InvalidAccessError", Code: 15}
+	ErrValidation            = &DOMException{Name: "ValidationError", Code: 16}
+	ErrTypeMismatch          = &DOMException{Name: "TypeMismatchError", Code: 17}
+	ErrSecurity              = &DOMException{Name: "SecurityError", Code: 18}
+	ErrNetwork               = &DOMException{Name: "NetworkError", Code: 19}
+	ErrAbort                 = &DOMException{Name: "AbortError", Code: 20}
+	ErrURLMismatch           = &DOMException{Name: "URLMismatchError", Code: 21}
+	ErrQuotaExceeded         = &DOMException{Name: "QuotaExceededError", Code: 22}
+	ErrTimeout               = &DOMException{Name: "TimeoutError", Code: 23}
+	ErrInvalidNodeType       = &DOMException{Name: "InvalidNodeTypeError", Code: 24}
+	ErrDataClone             = &DOMException{Name: "DataCloneError", Code: 25}
+	ErrEncoding              = &DOMException{Name: "EncodingError"}
+	ErrNotReadable           = &DOMException{Name: "NotReadableError"}
+	ErrUnknown               = &DOMException{Name: "UnknownError"}
+	ErrConstraint            = &DOMException{Name: "ConstraintError"}
+	ErrData                  = &DOMException{Name: "DataError"}
+	ErrTransactionInactive   = &DOMException{Name: "TransactionInactiveError"}
+	ErrReadOnly              = &DOMException{Name: "ReadOnlyError"}
+	ErrVersion               = &DOMException{Name: "VersionError"}
+	ErrOperation             = &DOMException{Name: "OperationError"}
+	ErrNotAllowed            = &DOMException{Name: "NotAllowedError"}
+)
+
+// wrapError converts the JavaScript exception v to a Go error. A
+// DOMException becomes a *DOMException, anything else a js.Error.
+func wrapError(v js.Value) error {
+	// Checking the object's class instead of using instanceof also
+	// recognizes exceptions from other realms, such as iframes.
+	if v.Type() == js.TypeObject &&
+		js.Global().Get("Object").Get("prototype").Get("toString").Call("call", v).String() == "[object DOMException]" {
+		return &DOMException{
+			Name:    v.Get("name").String(),
+			Code:    v.Get("code").Int(),
+			Message: v.Get("message").String(),
+			Value:   v,
+		}
+	}
+	return js.Error{Value: v}
+}
+
+// recoverError recovers from a panic caused by a JavaScript exception
+// and stores it in *err. Other panics, such as those caused by bugs in
+// Go code, are propagated.
+//
+// Only exceptions thrown by js.Value.Call, Invoke and New can be
+// recovered from. Exceptions thrown by property accessors abort the
+// program instead, so use getRecover and setRecover for properties
+// that may throw.
+func recoverError(err *error) {
+	e := recover()
+	if e == nil {
+		return
+	}
+	if jsErr, ok := e.(js.Error); ok {
+		*err = wrapError(jsErr.Value)
+		return
+	}
+	panic(e)
+}
+
+func callRecover(o js.Value, fn string, args ...interface{}) (err error) {
+	_, err = callValueRecover(o, fn, args...)
+	return err
+}
+
+// callValueRecover is like callRecover, but also returns the result of
+// the call.
+func callValueRecover(o js.Value, fn string, args ...interface{}) (v js.Value, err error) {
+	defer recoverError(&err)
+	return o.Call(fn, args...), nil
+}
+
+// getRecover returns the property p of o and returns any exception
+// thrown by a getter as an error. It uses Reflect.get because, unlike
+// js.Value.Get, method calls catch exceptions.
+func getRecover(o js.Value, p string) (js.Value, error) {
+	return callValueRecover(js.Global().Get("Reflect"), "get", o, p)
+}
+
+// setRecover sets the property p of o to x and returns any exception
+// thrown by a setter as an error. Like getRecover, it goes through
+// Reflect.
+func setRecover(o js.Value, p string, x interface{}) error {
+	return callRecover(js.Global().Get("Reflect"), "set", o, p, x)
+}
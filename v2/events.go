@@ -4,6 +4,7 @@
 package dom
 
 import (
+	"sync"
 	"syscall/js"
 	"time"
 )
@@ -17,101 +18,79 @@ func wrapEvent(o js.Value) Event {
 		return nil
 	}
 	ev := &BasicEvent{o}
-	c := o.Get("constructor")
-	switch {
-	case c.Equal(js.Global().Get("AnimationEvent")):
-		return &AnimationEvent{ev}
-	case c.Equal(js.Global().Get("AudioProcessingEvent")):
-		return &AudioProcessingEvent{ev}
-	case c.Equal(js.Global().Get("BeforeInputEvent")):
-		return &BeforeInputEvent{ev}
-	case c.Equal(js.Global().Get("BeforeUnloadEvent")):
-		return &BeforeUnloadEvent{ev}
-	case c.Equal(js.Global().Get("BlobEvent")):
-		return &BlobEvent{ev}
-	case c.Equal(js.Global().Get("ClipboardEvent")):
-		return &ClipboardEvent{ev}
-	case c.Equal(js.Global().Get("CloseEvent")):
-		return &CloseEvent{BasicEvent: ev}
-	case c.Equal(js.Global().Get("CompositionEvent")):
-		return &CompositionEvent{ev}
-	case c.Equal(js.Global().Get("CSSFontFaceLoadEvent")):
-		return &CSSFontFaceLoadEvent{ev}
-	case c.Equal(js.Global().Get("CustomEvent")):
-		return &CustomEvent{ev}
-	case c.Equal(js.Global().Get("DeviceLightEvent")):
-		return &DeviceLightEvent{ev}
-	case c.Equal(js.Global().Get("DeviceMotionEvent")):
-		return &DeviceMotionEvent{ev}
-	case c.Equal(js.Global().Get("DeviceOrientationEvent")):
-		return &DeviceOrientationEvent{ev}
-	case c.Equal(js.Global().Get("DeviceProximityEvent")):
-		return &DeviceProximityEvent{ev}
-	case c.Equal(js.Global().Get("DOMTransactionEvent")):
-		return &DOMTransactionEvent{ev}
-	case c.Equal(js.Global().Get("DragEvent")):
-		return &DragEvent{ev}
-	case c.Equal(js.Global().Get("EditingBeforeInputEvent")):
-		return &EditingBeforeInputEvent{ev}
-	case c.Equal(js.Global().Get("ErrorEvent")):
-		return &ErrorEvent{ev}
-	case c.Equal(js.Global().Get("FocusEvent")):
-		return &FocusEvent{ev}
-	case c.Equal(js.Global().Get("GamepadEvent")):
-		return &GamepadEvent{ev}
-	case c.Equal(js.Global().Get("HashChangeEvent")):
-		return &HashChangeEvent{ev}
-	case c.Equal(js.Global().Get("IDBVersionChangeEvent")):
-		return &IDBVersionChangeEvent{ev}
-	case c.Equal(js.Global().Get("KeyboardEvent")):
-		return &KeyboardEvent{BasicEvent: ev}
-	case c.Equal(js.Global().Get("MediaStreamEvent")):
-		return &MediaStreamEvent{ev}
-	case c.Equal(js.Global().Get("MessageEvent")):
-		return &MessageEvent{BasicEvent: ev}
-	case c.Equal(js.Global().Get("MouseEvent")):
-		return &MouseEvent{UIEvent: &UIEvent{ev}}
-	case c.Equal(js.Global().Get("MutationEvent")):
-		return &MutationEvent{ev}
-	case c.Equal(js.Global().Get("OfflineAudioCompletionEvent")):
-		return &OfflineAudioCompletionEvent{ev}
-	case c.Equal(js.Global().Get("PageTransitionEvent")):
-		return &PageTransitionEvent{ev}
-	case c.Equal(js.Global().Get("PointerEvent")):
-		return &PointerEvent{&MouseEvent{&UIEvent{ev}}}
-	case c.Equal(js.Global().Get("PopStateEvent")):
-		return &PopStateEvent{ev}
-	case c.Equal(js.Global().Get("ProgressEvent")):
-		return &ProgressEvent{ev}
-	case c.Equal(js.Global().Get("RelatedEvent")):
-		return &RelatedEvent{ev}
-	case c.Equal(js.Global().Get("RTCPeerConnectionIceEvent")):
-		return &RTCPeerConnectionIceEvent{ev}
-	case c.Equal(js.Global().Get("SensorEvent")):
-		return &SensorEvent{ev}
-	case c.Equal(js.Global().Get("StorageEvent")):
-		return &StorageEvent{ev}
-	case c.Equal(js.Global().Get("SVGEvent")):
-		return &SVGEvent{ev}
-	case c.Equal(js.Global().Get("SVGZoomEvent")):
-		return &SVGZoomEvent{ev}
-	case c.Equal(js.Global().Get("TimeEvent")):
-		return &TimeEvent{ev}
-	case c.Equal(js.Global().Get("TouchEvent")):
-		return &TouchEvent{BasicEvent: ev}
-	case c.Equal(js.Global().Get("TrackEvent")):
-		return &TrackEvent{ev}
-	case c.Equal(js.Global().Get("TransitionEvent")):
-		return &TransitionEvent{ev}
-	case c.Equal(js.Global().Get("UIEvent")):
-		return &UIEvent{ev}
-	case c.Equal(js.Global().Get("UserProximityEvent")):
-		return &UserProximityEvent{ev}
-	case c.Equal(js.Global().Get("WheelEvent")):
-		return &WheelEvent{MouseEvent: &MouseEvent{UIEvent: &UIEvent{ev}}}
-	default:
-		return ev
+	if i := eventConstructors().lookup(o.Get("constructor")); i >= 0 {
+		return eventWrappers[i].wrap(ev)
 	}
+	return ev
+}
+
+// eventWrappers maps the names of event constructors to functions
+// that wrap a BasicEvent in the corresponding concrete type.
+var eventWrappers = []struct {
+	name string
+	wrap func(ev *BasicEvent) Event
+}{
+	{"AnimationEvent", func(ev *BasicEvent) Event { return &AnimationEvent{ev} }},
+	{"AudioProcessingEvent", func(ev *BasicEvent) Event { return &AudioProcessingEvent{ev} }},
+	{"BeforeInputEvent", func(ev *BasicEvent) Event { return &BeforeInputEvent{ev} }},
+	{"BeforeUnloadEvent", func(ev *BasicEvent) Event { return &BeforeUnloadEvent{ev} }},
+	{"BlobEvent", func(ev *BasicEvent) Event { return &BlobEvent{ev} }},
+	{"ClipboardEvent", func(ev *BasicEvent) Event { return &ClipboardEvent{ev} }},
+	{"CloseEvent", func(ev *BasicEvent) Event { return &CloseEvent{BasicEvent: ev} }},
+	{"CompositionEvent", func(ev *BasicEvent) Event { return &CompositionEvent{ev} }},
+	{"CSSFontFaceLoadEvent", func(ev *BasicEvent) Event { return &CSSFontFaceLoadEvent{ev} }},
+	{"CustomEvent", func(ev *BasicEvent) Event { return &CustomEvent{ev} }},
+	{"DeviceLightEvent", func(ev *BasicEvent) Event { return &DeviceLightEvent{ev} }},
+	{"DeviceMotionEvent", func(ev *BasicEvent) Event { return &DeviceMotionEvent{ev} }},
+	{"DeviceOrientationEvent", func(ev *BasicEvent) Event { return &DeviceOrientationEvent{ev} }},
+	{"DeviceProximityEvent", func(ev *BasicEvent) Event { return &DeviceProximityEvent{ev} }},
+	{"DOMTransactionEvent", func(ev *BasicEvent) Event { return &DOMTransactionEvent{ev} }},
+	{"DragEvent", func(ev *BasicEvent) Event { return &DragEvent{ev} }},
+	{"EditingBeforeInputEvent", func(ev *BasicEvent) Event { return &EditingBeforeInputEvent{ev} }},
+	{"ErrorEvent", func(ev *BasicEvent) Event { return &ErrorEvent{ev} }},
+	{"FocusEvent", func(ev *BasicEvent) Event { return &FocusEvent{ev} }},
+	{"GamepadEvent", func(ev *BasicEvent) Event { return &GamepadEvent{ev} }},
+	{"HashChangeEvent", func(ev *BasicEvent) Event { return &HashChangeEvent{ev} }},
+	{"IDBVersionChangeEvent", func(ev *BasicEvent) Event { return &IDBVersionChangeEvent{ev} }},
+	{"KeyboardEvent", func(ev *BasicEvent) Event { return &KeyboardEvent{BasicEvent: ev} }},
+	{"MediaStreamEvent", func(ev *BasicEvent) Event { return &MediaStreamEvent{ev} }},
+	{"MessageEvent", func(ev *BasicEvent) Event { return &MessageEvent{BasicEvent: ev} }},
+	{"MouseEvent", func(ev *BasicEvent) Event { return &MouseEvent{UIEvent: &UIEvent{ev}} }},
+	{"MutationEvent", func(ev *BasicEvent) Event { return &MutationEvent{ev} }},
+	{"OfflineAudioCompletionEvent", func(ev *BasicEvent) Event { return &OfflineAudioCompletionEvent{ev} }},
+	{"PageTransitionEvent", func(ev *BasicEvent) Event { return &PageTransitionEvent{ev} }},
+	{"PointerEvent", func(ev *BasicEvent) Event { return &PointerEvent{&MouseEvent{&UIEvent{ev}}} }},
+	{"PopStateEvent", func(ev *BasicEvent) Event { return &PopStateEvent{ev} }},
+	{"ProgressEvent", func(ev *BasicEvent) Event { return &ProgressEvent{ev} }},
+	{"RelatedEvent", func(ev *BasicEvent) Event { return &RelatedEvent{ev} }},
+	{"RTCPeerConnectionIceEvent", func(ev *BasicEvent) Event { return &RTCPeerConnectionIceEvent{ev} }},
+	{"SensorEvent", func(ev *BasicEvent) Event { return &SensorEvent{ev} }},
+	{"StorageEvent", func(ev *BasicEvent) Event { return &StorageEvent{ev} }},
+	{"SVGEvent", func(ev *BasicEvent) Event { return &SVGEvent{ev} }},
+	{"SVGZoomEvent", func(ev *BasicEvent) Event { return &SVGZoomEvent{ev} }},
+	{"TimeEvent", func(ev *BasicEvent) Event { return &TimeEvent{ev} }},
+	{"TouchEvent", func(ev *BasicEvent) Event { return &TouchEvent{BasicEvent: ev} }},
+	{"TrackEvent", func(ev *BasicEvent) Event { return &TrackEvent{ev} }},
+	{"TransitionEvent", func(ev *BasicEvent) Event { return &TransitionEvent{ev} }},
+	{"UIEvent", func(ev *BasicEvent) Event { return &UIEvent{ev} }},
+	{"UserProximityEvent", func(ev *BasicEvent) Event { return &UserProximityEvent{ev} }},
+	{"WheelEvent", func(ev *BasicEvent) Event { return &WheelEvent{MouseEvent: &MouseEvent{UIEvent: &UIEvent{ev}}} }},
+}
+
+var (
+	eventConstructorsOnce sync.Once
+	eventConstructorTable *constructorTable
+)
+
+func eventConstructors() *constructorTable {
+	eventConstructorsOnce.Do(func() {
+		names := make([]string, len(eventWrappers))
+		for i, w := range eventWrappers {
+			names[i] = w.name
+		}
+		eventConstructorTable = newConstructorTable(js.Global(), names)
+	})
+	return eventConstructorTable
 }
 
 const (
@@ -125,14 +104,26 @@ type Event interface {
 	Bubbles() bool
 	Cancelable() bool
 	CurrentTarget() Element
+	// CurrentTargetAsEventTarget is like CurrentTarget, but doesn't
+	// assume that the current target is an element. It returns a
+	// Window, Document, Node or generic EventTarget, as appropriate.
+	CurrentTargetAsEventTarget() EventTarget
 	DefaultPrevented() bool
 	EventPhase() int
 	Target() Element
+	// TargetAsEventTarget is like Target, but doesn't assume that the
+	// target is an element. It returns a Window, Document, Node or
+	// generic EventTarget, as appropriate.
+	TargetAsEventTarget() EventTarget
 	Timestamp() time.Time
 	Type() string
 	PreventDefault()
 	StopImmediatePropagation()
 	StopPropagation()
+	// ComposedPath returns the event's path, starting with the
+	// innermost target. The path includes nodes in open shadow trees
+	// and ends with the Window, if any.
+	ComposedPath() []EventTarget
 	Underlying() js.Value
 }
 
@@ -165,6 +156,10 @@ func (ev *BasicEvent) CurrentTarget() Element {
 	return wrapElement(ev.Get("currentTarget"))
 }
 
+func (ev *BasicEvent) CurrentTargetAsEventTarget() EventTarget {
+	return wrapEventTarget(ev.Get("currentTarget"))
+}
+
 func (ev *BasicEvent) DefaultPrevented() bool {
 	return ev.Get("defaultPrevented").Bool()
 }
@@ -177,6 +172,10 @@ func (ev *BasicEvent) Target() Element {
 	return wrapElement(ev.Get("target"))
 }
 
+func (ev *BasicEvent) TargetAsEventTarget() EventTarget {
+	return wrapEventTarget(ev.Get("target"))
+}
+
 func (ev *BasicEvent) Timestamp() time.Time {
 	ms := ev.Get("timeStamp").Int()
 	s := ms / 1000
@@ -200,6 +199,15 @@ func (ev *BasicEvent) StopPropagation() {
 	ev.Call("stopPropagation")
 }
 
+func (ev *BasicEvent) ComposedPath() []EventTarget {
+	path := ev.Call("composedPath")
+	out := make([]EventTarget, path.Length())
+	for i := range out {
+		out[i] = wrapEventTarget(path.Index(i))
+	}
+	return out
+}
+
 func (ev *BasicEvent) Underlying() js.Value {
 	return ev.Value
 }
@@ -234,8 +242,17 @@ type DeviceProximityEvent struct{ *BasicEvent }
 type DOMTransactionEvent struct{ *BasicEvent }
 type DragEvent struct{ *BasicEvent }
 type EditingBeforeInputEvent struct{ *BasicEvent }
+
 type ErrorEvent struct{ *BasicEvent }
 
+func (ev *ErrorEvent) Message() string  { return ev.Get("message").String() }
+func (ev *ErrorEvent) Filename() string { return ev.Get("filename").String() }
+func (ev *ErrorEvent) Lineno() int      { return ev.Get("lineno").Int() }
+func (ev *ErrorEvent) Colno() int       { return ev.Get("colno").Int() }
+
+// Error returns the exception, which may be any JavaScript value.
+func (ev *ErrorEvent) Error() js.Value { return ev.Get("error") }
+
 type FocusEvent struct{ *BasicEvent }
 
 func (ev *FocusEvent) RelatedTarget() Element {
@@ -246,6 +263,18 @@ type GamepadEvent struct{ *BasicEvent }
 type HashChangeEvent struct{ *BasicEvent }
 type IDBVersionChangeEvent struct{ *BasicEvent }
 
+func (ev *IDBVersionChangeEvent) OldVersion() int { return ev.Get("oldVersion").Int() }
+
+// NewVersion returns the new version of the database, or 0 if it is
+// being deleted.
+func (ev *IDBVersionChangeEvent) NewVersion() int {
+	v := ev.Get("newVersion")
+	if v.IsNull() {
+		return 0
+	}
+	return v.Int()
+}
+
 const (
 	KeyLocationStandard = 0
 	KeyLocationLeft     = 1
@@ -277,12 +306,48 @@ func (ev *KeyboardEvent) ModifierState(mod string) bool {
 
 type MediaStreamEvent struct{ *BasicEvent }
 
+// MessageEvent is a message received from another window, a worker,
+// a MessagePort, a BroadcastChannel or a WebSocket.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/MessageEvent.
 type MessageEvent struct {
 	*BasicEvent
 }
 
 func (ev *MessageEvent) Data() js.Value { return ev.Get("data") }
 
+// DecodeData decodes the message into dst, which must be a pointer.
+// A Uint8Array, which PostMessage sends for a []byte, is decoded into
+// a *[]byte; other messages are decoded as with encoding/json, which
+// reverses PostMessage's conversion of other values, including byte
+// slices nested in maps, slices or structs.
+func (ev *MessageEvent) DecodeData(dst interface{}) error { return jsToGo(ev.Data(), dst) }
+
+// Origin returns the origin of the sender, for messages from windows
+// and server-sent events.
+func (ev *MessageEvent) Origin() string { return ev.Get("origin").String() }
+
+// LastEventID returns the ID of server-sent events.
+func (ev *MessageEvent) LastEventID() string { return ev.Get("lastEventId").String() }
+
+// Source returns the sender, which is a Window, a *MessagePort or a
+// service worker, or nil if it is unknown.
+func (ev *MessageEvent) Source() EventTarget { return wrapEventTarget(ev.Get("source")) }
+
+// Ports returns the MessagePorts that were transferred with the
+// message.
+func (ev *MessageEvent) Ports() []*MessagePort {
+	ports := ev.Get("ports")
+	if ports.IsUndefined() || ports.IsNull() {
+		return nil
+	}
+	out := make([]*MessagePort, ports.Length())
+	for i := range out {
+		out[i] = &MessagePort{&BasicEventTarget{ports.Index(i)}}
+	}
+	return out
+}
+
 type MouseEvent struct {
 	*UIEvent
 }
@@ -321,7 +386,31 @@ type ProgressEvent struct{ *BasicEvent }
 type RelatedEvent struct{ *BasicEvent }
 type RTCPeerConnectionIceEvent struct{ *BasicEvent }
 type SensorEvent struct{ *BasicEvent }
+
+// StorageEvent is dispatched at a window when another document of the
+// same origin modifies local storage, or session storage in the same
+// tab.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/StorageEvent.
 type StorageEvent struct{ *BasicEvent }
+
+// Key returns the key of the changed item, or the empty string if the
+// storage was cleared.
+func (ev *StorageEvent) Key() string { return toString(ev.Get("key")) }
+
+// OldValue returns the previous value of the item, or the empty
+// string if the item was added.
+func (ev *StorageEvent) OldValue() string { return toString(ev.Get("oldValue")) }
+
+// NewValue returns the new value of the item, or the empty string if
+// the item was removed.
+func (ev *StorageEvent) NewValue() string { return toString(ev.Get("newValue")) }
+
+// URL returns the URL of the document that changed the storage.
+func (ev *StorageEvent) URL() string { return ev.Get("url").String() }
+
+func (ev *StorageEvent) StorageArea() *Storage { return wrapStorage(ev.Get("storageArea")) }
+
 type SVGEvent struct{ *BasicEvent }
 type SVGZoomEvent struct{ *BasicEvent }
 type TimeEvent struct{ *BasicEvent }
@@ -430,3 +519,68 @@ type EventTarget interface {
 	RemoveEventListener(typ string, useCapture bool, listener js.Func)
 	DispatchEvent(event Event) bool
 }
+
+// Type BasicEventTarget implements the EventTarget interface for
+// objects that have no more specific wrapper in this package, such as
+// XMLHttpRequest.
+type BasicEventTarget struct{ js.Value }
+
+func (t *BasicEventTarget) AddEventListener(typ string, useCapture bool, listener func(Event)) js.Func {
+	wrapper := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		listener(wrapEvent(args[0]))
+		return nil
+	})
+	t.Call("addEventListener", typ, wrapper, useCapture)
+	return wrapper
+}
+
+func (t *BasicEventTarget) RemoveEventListener(typ string, useCapture bool, listener js.Func) {
+	t.Call("removeEventListener", typ, listener, useCapture)
+	listener.Release()
+}
+
+func (t *BasicEventTarget) DispatchEvent(event Event) bool {
+	return t.Call("dispatchEvent", event.Underlying()).Bool()
+}
+
+func (t *BasicEventTarget) Underlying() js.Value {
+	return t.Value
+}
+
+func WrapEventTarget(o js.Value) EventTarget {
+	return wrapEventTarget(o)
+}
+
+// wrapEventTarget wraps o in the most specific EventTarget
+// implementation available: a Window, a Document, a DocumentFragment,
+// a Node, a ScreenOrientation or, failing all of those, a
+// BasicEventTarget.
+func wrapEventTarget(o js.Value) EventTarget {
+	if o.IsNull() || o.IsUndefined() {
+		return nil
+	}
+	if o.Get("window").Equal(o) {
+		return &window{o}
+	}
+	if nt := o.Get("nodeType"); nt.Type() == js.TypeNumber {
+		switch nt.Int() {
+		case 9:
+			return wrapDocument(o)
+		case 11:
+			return wrapDocumentFragment(o)
+		default:
+			return wrapNode(o)
+		}
+	}
+	switch c := o.Get("constructor"); {
+	case c.Equal(js.Global().Get("ScreenOrientation")):
+		return &ScreenOrientation{o}
+	case c.Equal(js.Global().Get("MessagePort")):
+		return &MessagePort{&BasicEventTarget{o}}
+	case c.Equal(js.Global().Get("Worker")):
+		return &Worker{&BasicEventTarget{o}}
+	case c.Equal(js.Global().Get("BroadcastChannel")):
+		return &BroadcastChannel{&BasicEventTarget{o}}
+	}
+	return &BasicEventTarget{o}
+}
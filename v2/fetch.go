@@ -0,0 +1,472 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"syscall/js"
+)
+
+// Headers is a list of HTTP headers.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/Headers.
+type Headers struct {
+	js.Value
+}
+
+// NewHeaders returns the headers in h. It panics if h contains an
+// invalid name or value; use NewHeadersErr to handle that case.
+func NewHeaders(h http.Header) *Headers {
+	o, err := NewHeadersErr(h)
+	if err != nil {
+		panic(err)
+	}
+	return o
+}
+
+// NewHeadersErr is like NewHeaders, but returns an error instead of
+// panicking if h contains an invalid name or value.
+func NewHeadersErr(h http.Header) (*Headers, error) {
+	o := &Headers{js.Global().Get("Headers").New()}
+	for name, values := range h {
+		for _, v := range values {
+			if err := o.AppendErr(name, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return o, nil
+}
+
+// Get returns the values of the header name, joined by commas, and
+// whether the header exists.
+func (h *Headers) Get(name string) (string, bool) {
+	v := h.Call("get", name)
+	if v.IsNull() {
+		return "", false
+	}
+	return v.String(), true
+}
+
+func (h *Headers) Has(name string) bool { return h.Call("has", name).Bool() }
+func (h *Headers) Delete(name string)   { h.Call("delete", name) }
+
+// Set replaces the values of the header name with value. It panics if
+// name or value is invalid, or if the headers are immutable, such as
+// those of a response; use SetErr to handle these cases.
+func (h *Headers) Set(name, value string) {
+	if err := h.SetErr(name, value); err != nil {
+		panic(err)
+	}
+}
+
+// SetErr is like Set, but returns an error instead of panicking.
+func (h *Headers) SetErr(name, value string) error {
+	return callRecover(h.Value, "set", name, value)
+}
+
+// Append adds value to the values of the header name. It panics in the
+// same cases as Set; use AppendErr to handle them.
+func (h *Headers) Append(name, value string) {
+	if err := h.AppendErr(name, value); err != nil {
+		panic(err)
+	}
+}
+
+// AppendErr is like Append, but returns an error instead of panicking.
+func (h *Headers) AppendErr(name, value string) error {
+	return callRecover(h.Value, "append", name, value)
+}
+
+// Entries returns all name-value pairs, with lowercase names, sorted
+// by name.
+func (h *Headers) Entries() [][2]string {
+	a := js.Global().Get("Array").Call("from", h.Value)
+	out := make([][2]string, a.Length())
+	for i := range out {
+		e := a.Index(i)
+		out[i] = [2]string{e.Index(0).String(), e.Index(1).String()}
+	}
+	return out
+}
+
+// HTTPHeader converts the headers to an http.Header.
+func (h *Headers) HTTPHeader() http.Header {
+	out := http.Header{}
+	for _, e := range h.Entries() {
+		out.Add(e[0], e[1])
+	}
+	return out
+}
+
+// RequestMode determines whether cross-origin requests are allowed.
+type RequestMode string
+
+const (
+	RequestModeCORS       RequestMode = "cors"
+	RequestModeNoCORS     RequestMode = "no-cors"
+	RequestModeSameOrigin RequestMode = "same-origin"
+	// RequestModeNavigate is only used by the browser for navigation
+	// requests, such as those seen by service workers. NewRequest
+	// rejects it.
+	RequestModeNavigate RequestMode = "navigate"
+)
+
+// RequestCredentials determines whether cookies and HTTP
+// authentication are sent with requests.
+type RequestCredentials string
+
+const (
+	RequestCredentialsOmit       RequestCredentials = "omit"
+	RequestCredentialsSameOrigin RequestCredentials = "same-origin"
+	RequestCredentialsInclude    RequestCredentials = "include"
+)
+
+// RequestCache determines how requests interact with the browser's
+// HTTP cache.
+type RequestCache string
+
+const (
+	RequestCacheDefault      RequestCache = "default"
+	RequestCacheNoStore      RequestCache = "no-store"
+	RequestCacheReload       RequestCache = "reload"
+	RequestCacheNoCache      RequestCache = "no-cache"
+	RequestCacheForceCache   RequestCache = "force-cache"
+	RequestCacheOnlyIfCached RequestCache = "only-if-cached"
+)
+
+// RequestRedirect determines how redirects are handled.
+type RequestRedirect string
+
+const (
+	RequestRedirectFollow RequestRedirect = "follow"
+	RequestRedirectError  RequestRedirect = "error"
+	RequestRedirectManual RequestRedirect = "manual"
+)
+
+// RequestPriority is the priority of a request relative to other
+// requests.
+type RequestPriority string
+
+const (
+	RequestPriorityHigh RequestPriority = "high"
+	RequestPriorityLow  RequestPriority = "low"
+	RequestPriorityAuto RequestPriority = "auto"
+)
+
+// RequestInit are the options for creating a Request. Empty fields
+// use the browser's defaults.
+type RequestInit struct {
+	// Method defaults to GET.
+	Method  string
+	Headers http.Header
+	// Body is read completely when the request is created.
+	Body        io.Reader
+	Mode        RequestMode
+	Credentials RequestCredentials
+	Cache       RequestCache
+	Redirect    RequestRedirect
+	// Referrer is a same-origin URL or "about:client". The empty string
+	// means the default referrer, unless ReferrerPolicy is
+	// "no-referrer", in which case no referrer is sent.
+	Referrer string
+	// ReferrerPolicy is one of the values of the Referrer-Policy
+	// header.
+	ReferrerPolicy string
+	Integrity      string
+	// Keepalive allows the request to outlive the page.
+	Keepalive bool
+	Priority  RequestPriority
+}
+
+// Request is an HTTP request for Fetch.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/Request.
+type Request struct {
+	js.Value
+}
+
+// NewRequest returns a request for url. It returns an error if url is
+// invalid, if init contains invalid options, or if reading the body
+// fails.
+func NewRequest(url string, init RequestInit) (*Request, error) {
+	o := map[string]interface{}{}
+	set := func(name, v string) {
+		if v != "" {
+			o[name] = v
+		}
+	}
+	set("method", init.Method)
+	set("mode", string(init.Mode))
+	set("credentials", string(init.Credentials))
+	set("cache", string(init.Cache))
+	set("redirect", string(init.Redirect))
+	set("referrerPolicy", init.ReferrerPolicy)
+	set("integrity", init.Integrity)
+	set("priority", string(init.Priority))
+	if init.Referrer != "" || init.ReferrerPolicy == "no-referrer" {
+		o["referrer"] = init.Referrer
+	}
+	if init.Headers != nil {
+		h, err := NewHeadersErr(init.Headers)
+		if err != nil {
+			return nil, err
+		}
+		o["headers"] = h.Value
+	}
+	if init.Keepalive {
+		o["keepalive"] = true
+	}
+	if init.Body != nil {
+		b, err := ioutil.ReadAll(init.Body)
+		if err != nil {
+			return nil, err
+		}
+		body := js.Global().Get("Uint8Array").New(len(b))
+		js.CopyBytesToJS(body, b)
+		o["body"] = body
+	}
+	return newRequest(url, o)
+}
+
+func newRequest(args ...interface{}) (r *Request, err error) {
+	defer recoverError(&err)
+	return &Request{js.Global().Get("Request").New(args...)}, nil
+}
+
+// NewRequestFromHTTP converts an http.Request, reading its body. The
+// request's context isn't used; pass it to Fetch instead.
+func NewRequestFromHTTP(r *http.Request) (*Request, error) {
+	init := RequestInit{Method: r.Method, Headers: r.Header}
+	if r.Body != nil && r.Body != http.NoBody {
+		init.Body = r.Body
+		defer r.Body.Close()
+	}
+	return NewRequest(r.URL.String(), init)
+}
+
+func (r *Request) Method() string    { return r.Get("method").String() }
+func (r *Request) URL() string       { return r.Get("url").String() }
+func (r *Request) Headers() *Headers { return &Headers{r.Get("headers")} }
+func (r *Request) Mode() RequestMode { return RequestMode(r.Get("mode").String()) }
+func (r *Request) Credentials() RequestCredentials {
+	return RequestCredentials(r.Get("credentials").String())
+}
+func (r *Request) Cache() RequestCache       { return RequestCache(r.Get("cache").String()) }
+func (r *Request) Redirect() RequestRedirect { return RequestRedirect(r.Get("redirect").String()) }
+func (r *Request) Referrer() string          { return r.Get("referrer").String() }
+func (r *Request) ReferrerPolicy() string    { return r.Get("referrerPolicy").String() }
+func (r *Request) Keepalive() bool           { return r.Get("keepalive").Bool() }
+
+// Clone returns a copy of the request, which can be fetched
+// separately.
+func (r *Request) Clone() *Request { return &Request{r.Call("clone")} }
+
+// HTTPRequest converts the request to an http.Request with the context
+// ctx. It reads a copy of the body, so the request can still be
+// fetched. Like all blocking functions, it must not be called directly
+// from a JavaScript callback such as an event listener.
+func (r *Request) HTTPRequest(ctx context.Context) (*http.Request, error) {
+	var body []byte
+	if m := r.Method(); m != "GET" && m != "HEAD" {
+		c, err := callValueRecover(r.Value, "clone")
+		if err != nil {
+			return nil, err
+		}
+		v, err := await(c.Call("arrayBuffer"))
+		if err != nil {
+			return nil, err
+		}
+		body = make([]byte, v.Get("byteLength").Int())
+		js.CopyBytesToGo(body, js.Global().Get("Uint8Array").New(v))
+	}
+	req, err := http.NewRequestWithContext(ctx, r.Method(), r.URL(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = r.Headers().HTTPHeader()
+	return req, nil
+}
+
+// Response is the response to a Fetch.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/Response.
+type Response struct {
+	js.Value
+	// Body streams the response body. It must be closed, which also
+	// releases the resources associated with the context passed to
+	// Fetch.
+	Body io.ReadCloser
+}
+
+func (r *Response) Status() int        { return r.Get("status").Int() }
+func (r *Response) StatusText() string { return r.Get("statusText").String() }
+func (r *Response) OK() bool           { return r.Get("ok").Bool() }
+func (r *Response) URL() string        { return r.Get("url").String() }
+func (r *Response) Redirected() bool   { return r.Get("redirected").Bool() }
+func (r *Response) Headers() *Headers  { return &Headers{r.Get("headers")} }
+
+// Type is "basic", "cors", "error", "opaque" or "opaqueredirect".
+// Opaque responses to no-cors requests have status 0 and no headers
+// or body.
+func (r *Response) Type() string { return r.Get("type").String() }
+
+// HTTPResponse converts the response to an http.Response, which takes
+// over the response's body. req may be nil.
+func (r *Response) HTTPResponse(req *http.Request) *http.Response {
+	h := r.Headers().HTTPHeader()
+	length := int64(-1)
+	if n, err := strconv.ParseInt(h.Get("Content-Length"), 10, 64); err == nil {
+		length = n
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(r.Status()) + " " + r.StatusText(),
+		StatusCode:    r.Status(),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          r.Body,
+		ContentLength: length,
+		Request:       req,
+	}
+}
+
+// fetch fetches req in the realm of global, aborting the request when
+// ctx is done.
+func fetch(ctx context.Context, global js.Value, req *Request) (*Response, error) {
+	var opts interface{}
+	stop := func() {}
+	if done := ctx.Done(); done != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ac := global.Get("AbortController").New()
+		opts = map[string]interface{}{"signal": ac.Get("signal")}
+		stopped := make(chan struct{})
+		go func() {
+			select {
+			case <-done:
+				ac.Call("abort")
+			case <-stopped:
+			}
+		}()
+		stop = func() { close(stopped) }
+	}
+
+	p, err := callValueRecover(global, "fetch", req.Value, opts)
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	v, err := await(p)
+	if err != nil {
+		stop()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	res := &Response{Value: v}
+	res.Body = newBodyReader(ctx, v, stop)
+	return res, nil
+}
+
+// bodyReader reads a response body from a ReadableStream.
+type bodyReader struct {
+	ctx    context.Context
+	res    js.Value
+	reader js.Value // the stream's reader, or undefined before the first read
+	buf    []byte   // the unread part of the current chunk
+	err    error
+	stop   func()
+	closed bool
+}
+
+func newBodyReader(ctx context.Context, res js.Value, stop func()) io.ReadCloser {
+	if res.Get("body").IsNull() {
+		stop()
+		return http.NoBody
+	}
+	return &bodyReader{ctx: ctx, res: res, stop: stop}
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	if b.closed {
+		return 0, errors.New("dom: read on closed response body")
+	}
+	for len(b.buf) == 0 && b.err == nil {
+		b.next()
+	}
+	if len(b.buf) == 0 {
+		return 0, b.err
+	}
+	n := copy(p, b.buf)
+	b.buf = b.buf[n:]
+	return n, nil
+}
+
+// next reads the next chunk into buf, or sets err.
+func (b *bodyReader) next() {
+	if b.reader.IsUndefined() {
+		body := b.res.Get("body")
+		if body.Get("getReader").IsUndefined() {
+			// Browsers without streaming support.
+			v, err := await(b.res.Call("arrayBuffer"))
+			if err != nil {
+				b.fail(err)
+				return
+			}
+			b.buf = make([]byte, v.Get("byteLength").Int())
+			js.CopyBytesToGo(b.buf, js.Global().Get("Uint8Array").New(v))
+			b.err = io.EOF
+			return
+		}
+		b.reader = body.Call("getReader")
+	}
+	v, err := await(b.reader.Call("read"))
+	if err != nil {
+		b.fail(err)
+		return
+	}
+	if v.Get("done").Bool() {
+		b.err = io.EOF
+		return
+	}
+	chunk := v.Get("value")
+	b.buf = make([]byte, chunk.Length())
+	js.CopyBytesToGo(b.buf, chunk)
+}
+
+func (b *bodyReader) fail(err error) {
+	if b.ctx.Err() != nil {
+		err = b.ctx.Err()
+	}
+	b.err = err
+}
+
+func (b *bodyReader) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	if b.err != io.EOF {
+		// Cancelling returns a promise, which there is no need to wait
+		// for. Before the first read, the stream itself has to be
+		// cancelled, unless the browser doesn't support streams.
+		if !b.reader.IsUndefined() {
+			b.reader.Call("cancel")
+		} else if body := b.res.Get("body"); !body.Get("cancel").IsUndefined() {
+			body.Call("cancel")
+		}
+	}
+	b.stop()
+	return nil
+}
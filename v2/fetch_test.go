@@ -0,0 +1,159 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+func TestFetch(t *testing.T) {
+	if js.Global().Get("fetch").IsUndefined() {
+		t.Skip("fetch is not available")
+	}
+	req, err := NewRequest("data:text/plain,hello%20world", RequestInit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := fetch(context.Background(), js.Global(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hres := res.HTTPResponse(nil)
+	body, err := ioutil.ReadAll(hres.Body)
+	hres.Body.Close()
+	if err != nil || string(body) != "hello world" {
+		t.Errorf("got (%q, %v), want hello world", body, err)
+	}
+	if hres.StatusCode != 200 || hres.Header.Get("Content-Type") != "text/plain" {
+		t.Errorf("got status %d and content type %q", hres.StatusCode, hres.Header.Get("Content-Type"))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := fetch(ctx, js.Global(), req); err != context.Canceled {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+}
+
+func TestNewRequestFromHTTP(t *testing.T) {
+	hreq, _ := http.NewRequest("POST", "https://example.com/items?x=1", strings.NewReader("payload"))
+	hreq.Header.Set("X-Test", "a")
+	req, err := NewRequestFromHTTP(hreq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method() != "POST" || req.URL() != "https://example.com/items?x=1" {
+		t.Errorf("got %s %s", req.Method(), req.URL())
+	}
+	if v, ok := req.Headers().Get("x-test"); !ok || v != "a" {
+		t.Errorf("got header %q, %t, want a", v, ok)
+	}
+	text, err := await(req.Call("text"))
+	if err != nil || text.String() != "payload" {
+		t.Errorf("got body (%v, %v), want payload", text, err)
+	}
+}
+
+func TestInvalidHeaders(t *testing.T) {
+	if js.Global().Get("Headers").IsUndefined() {
+		t.Skip("Headers is not available")
+	}
+	bad := http.Header{"Bad Name": {"x"}}
+	if _, err := NewHeadersErr(bad); err == nil {
+		t.Error("NewHeadersErr accepted an invalid name")
+	}
+	if _, err := NewRequest("https://example.com/", RequestInit{Headers: bad}); err == nil {
+		t.Error("NewRequest accepted an invalid header name")
+	}
+	h := NewHeaders(http.Header{"X-Test": {"a"}})
+	if err := h.SetErr("X-Test", "line\nbreak"); err == nil {
+		t.Error("SetErr accepted an invalid value")
+	}
+	if err := h.AppendErr("Bad Name", "x"); err == nil {
+		t.Error("AppendErr accepted an invalid name")
+	}
+	if v, _ := h.Get("x-test"); v != "a" {
+		t.Errorf("got %q after failed changes, want a", v)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Set didn't panic for an invalid name")
+		}
+	}()
+	h.Set("Bad Name", "x")
+}
+
+func TestRequestHTTPRequest(t *testing.T) {
+	req, err := NewRequest("https://example.com/items", RequestInit{
+		Method:  "PUT",
+		Headers: http.Header{"X-Test": {"a"}},
+		Body:    strings.NewReader("payload"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hreq, err := req.HTTPRequest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hreq.Method != "PUT" || hreq.URL.String() != "https://example.com/items" || hreq.Context() != ctx {
+		t.Errorf("got %s %s", hreq.Method, hreq.URL)
+	}
+	if got := hreq.Header.Get("X-Test"); got != "a" {
+		t.Errorf("got header %q, want a", got)
+	}
+	b, err := ioutil.ReadAll(hreq.Body)
+	if err != nil || string(b) != "payload" {
+		t.Errorf("got body (%q, %v), want payload", b, err)
+	}
+	if req.Get("bodyUsed").Bool() {
+		t.Error("HTTPRequest consumed the request's body")
+	}
+}
+
+func TestBodyReaderCloseUnread(t *testing.T) {
+	if js.Global().Get("Response").IsUndefined() {
+		t.Skip("Response is not available")
+	}
+	res := js.Global().Get("Response").New("unread")
+	stopped := false
+	r := newBodyReader(context.Background(), res, func() { stopped = true })
+	r.Close()
+	if !stopped {
+		t.Error("Close didn't stop the request")
+	}
+	// Cancelling a stream disturbs it, which marks the body as used.
+	if !res.Get("bodyUsed").Bool() {
+		t.Error("Close didn't cancel the unread body")
+	}
+}
+
+func TestBodyReader(t *testing.T) {
+	if js.Global().Get("Response").IsUndefined() {
+		t.Skip("Response is not available")
+	}
+	long := strings.Repeat("0123456789", 10000)
+	res := js.Global().Get("Response").New(long)
+	r := newBodyReader(context.Background(), res, func() {})
+	buf := make([]byte, 7)
+	var got []byte
+	for {
+		n, err := r.Read(buf)
+		got = append(got, buf[:n]...)
+		if err != nil {
+			break
+		}
+	}
+	r.Close()
+	if string(got) != long {
+		t.Errorf("got %d bytes, want %d", len(got), len(long))
+	}
+}
@@ -0,0 +1,160 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"time"
+)
+
+// ErrNotAWindow is returned by GetWindowErr, and the value GetWindow
+// panics with, when the global object isn't a window, such as in a
+// worker.
+var ErrNotAWindow = errors.New("dom: global object is not a Window")
+
+// ErrNoPostMessage is returned by WindowOrWorkerGlobalScope.PostMessage
+// when the global object has no postMessage method, such as in shared
+// workers.
+var ErrNoPostMessage = errors.New("dom: global object has no postMessage method")
+
+// GetWindowErr is like GetWindow, but returns ErrNotAWindow instead of
+// panicking when the program isn't running in a window.
+func GetWindowErr() (Window, error) {
+	global := js.Global()
+	if !isWindow(global) {
+		return nil, ErrNotAWindow
+	}
+	return &window{global}, nil
+}
+
+// isWindow reports whether o is a window, which, unlike other global
+// objects, refers to itself as window.
+func isWindow(o js.Value) bool {
+	return o.Get("window").Equal(o)
+}
+
+// WindowOrWorkerGlobalScope is the part of the global object's API
+// that is available both in windows and in workers.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/WindowOrWorkerGlobalScope.
+type WindowOrWorkerGlobalScope interface {
+	EventTarget
+
+	ClearInterval(int)
+	ClearTimeout(int)
+	Crypto() *Crypto
+	// Fetch is like Window.Fetch.
+	Fetch(ctx context.Context, req *Request) (*Response, error)
+	// IndexedDB returns the IndexedDB factory, or nil if IndexedDB
+	// isn't available.
+	IndexedDB() *IDBFactory
+	Performance() *Performance
+	// PostMessage calls the global object's postMessage method. In a
+	// window, this sends message to the window itself, restricted to
+	// the same origin; in a dedicated worker, it sends message to the
+	// worker's creator. Other global objects, such as those of shared
+	// workers, don't have a postMessage method, and PostMessage returns
+	// ErrNoPostMessage. message and transfer are converted like for
+	// Window.PostMessage.
+	PostMessage(message interface{}, transfer []interface{}) error
+	// QueueMicrotask schedules fn to be called once the current task
+	// and all previously queued microtasks have finished.
+	QueueMicrotask(fn func())
+	SetInterval(fn func(), delay int) int
+	SetTimeout(fn func(), delay int) int
+}
+
+// GetGlobalScope returns the global object, which works both in
+// windows and in workers. Use GetWindowErr or
+// GetDedicatedWorkerGlobalScope to access the rest of the API of a
+// specific kind of global object.
+func GetGlobalScope() WindowOrWorkerGlobalScope {
+	return &globalScope{&BasicEventTarget{js.Global()}}
+}
+
+type globalScope struct {
+	*BasicEventTarget
+}
+
+// The methods that windows and workers have in common are implemented
+// by window, which makes no assumptions about its value being a
+// window.
+
+func (s *globalScope) ClearInterval(id int)   { (&window{s.Value}).ClearInterval(id) }
+func (s *globalScope) ClearTimeout(id int)    { (&window{s.Value}).ClearTimeout(id) }
+func (s *globalScope) Crypto() *Crypto        { return (&window{s.Value}).Crypto() }
+func (s *globalScope) IndexedDB() *IDBFactory { return (&window{s.Value}).IndexedDB() }
+func (s *globalScope) Performance() *Performance {
+	return (&window{s.Value}).Performance()
+}
+func (s *globalScope) QueueMicrotask(fn func()) { (&window{s.Value}).QueueMicrotask(fn) }
+
+func (s *globalScope) Fetch(ctx context.Context, req *Request) (*Response, error) {
+	return fetch(ctx, s.Value, req)
+}
+
+func (s *globalScope) PostMessage(message interface{}, transfer []interface{}) error {
+	if isWindow(s.Value) {
+		return postMessage(s.Value, message, transfer, "/")
+	}
+	if s.Get("postMessage").Type() != js.TypeFunction {
+		return ErrNoPostMessage
+	}
+	return postMessage(s.Value, message, transfer)
+}
+
+func (s *globalScope) SetInterval(fn func(), delay int) int {
+	return (&window{s.Value}).SetInterval(fn, delay)
+}
+
+func (s *globalScope) SetTimeout(fn func(), delay int) int {
+	return (&window{s.Value}).SetTimeout(fn, delay)
+}
+
+// Crypto provides cryptographically strong random numbers and, via
+// Subtle, cryptographic primitives.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/Crypto.
+type Crypto struct {
+	js.Value
+}
+
+// GetRandomValues fills b with random bytes. It returns
+// ErrQuotaExceeded if b is longer than 65536 bytes.
+func (c *Crypto) GetRandomValues(b []byte) (err error) {
+	defer recoverError(&err)
+	a := js.Global().Get("Uint8Array").New(len(b))
+	c.Call("getRandomValues", a)
+	js.CopyBytesToGo(b, a)
+	return nil
+}
+
+// RandomUUID returns a random version 4 UUID. It is only available in
+// secure contexts.
+func (c *Crypto) RandomUUID() string { return c.Call("randomUUID").String() }
+
+// Subtle returns the SubtleCrypto object, for which this package has
+// no bindings.
+func (c *Crypto) Subtle() js.Value { return c.Get("subtle") }
+
+// Performance provides access to high resolution timing information.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/Performance.
+type Performance struct {
+	js.Value
+}
+
+// Now returns the time elapsed since TimeOrigin.
+func (p *Performance) Now() time.Duration { return wrapDOMHighResTimeStamp(p.Call("now")) }
+
+// TimeOrigin returns the time at which the current window or worker
+// was created.
+func (p *Performance) TimeOrigin() time.Time {
+	return time.Unix(0, int64(p.Get("timeOrigin").Float()*float64(time.Millisecond)))
+}
+
+// Mark records a performance mark called name.
+func (p *Performance) Mark(name string) { p.Call("mark", name) }
@@ -0,0 +1,71 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"errors"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+func TestGetWindowErr(t *testing.T) {
+	global := js.Global()
+	old := global.Get("window")
+	defer global.Set("window", old)
+
+	global.Set("window", js.Undefined())
+	if _, err := GetWindowErr(); !errors.Is(err, ErrNotAWindow) {
+		t.Errorf("got error %v, want ErrNotAWindow", err)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != ErrNotAWindow {
+				t.Errorf("GetWindow panicked with %v, want ErrNotAWindow", r)
+			}
+		}()
+		GetWindow()
+	}()
+
+	global.Set("window", global)
+	if w, err := GetWindowErr(); err != nil || w == nil {
+		t.Errorf("got (%v, %v) for a window", w, err)
+	}
+}
+
+func TestGlobalScope(t *testing.T) {
+	s := GetGlobalScope()
+
+	// Node.js returns objects instead of IDs from its timer functions,
+	// so only microtasks can be tested here.
+	done := make(chan struct{})
+	s.QueueMicrotask(func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+	}
+
+	if err := s.PostMessage("hi", nil); !errors.Is(err, ErrNoPostMessage) {
+		t.Errorf("got error %v, want ErrNoPostMessage", err)
+	}
+
+	if p := s.Performance(); !p.Truthy() {
+		t.Log("performance is not available")
+	} else if p.Now() <= 0 || p.TimeOrigin().Before(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got Now %v and TimeOrigin %v", p.Now(), p.TimeOrigin())
+	}
+
+	if c := s.Crypto(); !c.Truthy() {
+		t.Log("crypto is not available")
+	} else {
+		b := make([]byte, 32)
+		if err := c.GetRandomValues(b); err != nil {
+			t.Error(err)
+		}
+		if err := c.GetRandomValues(make([]byte, 65537)); !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("got error %v, want ErrQuotaExceeded", err)
+		}
+	}
+}
@@ -0,0 +1,773 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"reflect"
+	"syscall/js"
+	"time"
+)
+
+// IDBFactory opens and deletes IndexedDB databases.
+//
+// The functions and methods of the IndexedDB types that return errors
+// block until the underlying IDBRequest has succeeded or failed. Like
+// all blocking functions, they must not be called directly from a
+// JavaScript callback such as an event listener.
+//
+// An IndexedDB transaction commits as soon as it has no outstanding
+// requests at the end of a task. Functions passed to
+// IDBDatabase.Update, IDBDatabase.View and Open may therefore block on
+// other IndexedDB requests, but must not block on anything else, such
+// as timers or network requests, or the transaction commits early and
+// later requests fail with ErrTransactionInactive.
+//
+// Values are converted between Go and JavaScript with encoding/json,
+// so struct tags control the property names of stored objects, which
+// key paths refer to. js.Values are stored and returned as is.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/IDBFactory.
+type IDBFactory struct {
+	js.Value
+}
+
+// Open opens the database called name, creating it if it doesn't
+// exist. If version is 0, the database is opened at its current
+// version, or at version 1 if it is created.
+//
+// If the database is older than version, upgrade is called to create
+// or modify its object stores and indexes; it may be nil if no
+// upgrade is ever needed. oldVersion is 0 if the database is being
+// created. If upgrade returns an error, the upgrade is aborted and Open
+// returns that error.
+//
+// If other connections to the database prevent an upgrade, Open waits
+// for them to be closed.
+func (f *IDBFactory) Open(name string, version int, upgrade func(db *IDBDatabase, oldVersion, newVersion int) error) (*IDBDatabase, error) {
+	args := []interface{}{name}
+	if version != 0 {
+		args = append(args, version)
+	}
+	r, err := callValueRecover(f.Value, "open", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		event string
+		ev    js.Value
+	}
+	ch := make(chan result, 1)
+	handler := func(event string) js.Func {
+		return js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+			ch <- result{event, args[0]}
+			return nil
+		})
+	}
+	onUpgrade, onSuccess, onError := handler("upgradeneeded"), handler("success"), handler("error")
+	defer onUpgrade.Release()
+	defer onSuccess.Release()
+	defer onError.Release()
+	r.Set("onupgradeneeded", onUpgrade)
+	r.Set("onsuccess", onSuccess)
+	r.Set("onerror", onError)
+
+	var upgradeErr error
+	for {
+		res := <-ch
+		switch res.event {
+		case "upgradeneeded":
+			if upgrade == nil {
+				continue
+			}
+			db := &IDBDatabase{Value: r.Get("result"), upgrade: r.Get("transaction")}
+			upgradeErr = upgrade(db, res.ev.Get("oldVersion").Int(), res.ev.Get("newVersion").Int())
+			if upgradeErr != nil {
+				// The transaction may already have been aborted by a
+				// failed request.
+				callRecover(db.upgrade, "abort")
+			}
+		case "success":
+			return &IDBDatabase{Value: r.Get("result")}, nil
+		case "error":
+			res.ev.Call("preventDefault")
+			if upgradeErr != nil {
+				return nil, upgradeErr
+			}
+			return nil, wrapError(r.Get("error"))
+		}
+	}
+}
+
+// DeleteDatabase deletes the database called name. It waits for
+// other connections to the database to be closed. Deleting a database
+// that doesn't exist is not an error.
+func (f *IDBFactory) DeleteDatabase(name string) error {
+	r, err := callValueRecover(f.Value, "deleteDatabase", name)
+	if err != nil {
+		return err
+	}
+	_, err = idbWait(r)
+	return err
+}
+
+// IDBDatabase is a connection to a database.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/IDBDatabase.
+type IDBDatabase struct {
+	js.Value
+	upgrade js.Value // the versionchange transaction during Open's upgrade
+}
+
+func (db *IDBDatabase) Name() string { return db.Get("name").String() }
+func (db *IDBDatabase) Version() int { return db.Get("version").Int() }
+
+func (db *IDBDatabase) ObjectStoreNames() []string {
+	return domStringListToStrings(db.Get("objectStoreNames"))
+}
+
+// Close closes the connection once all transactions have completed.
+func (db *IDBDatabase) Close() { db.Call("close") }
+
+// IDBObjectStoreOptions are the options for creating an object store.
+type IDBObjectStoreOptions struct {
+	// KeyPath is the property of stored values that contains their
+	// key. If it's empty, keys are stored separately from values, and
+	// either AutoIncrement must be true or keys must be passed
+	// explicitly with AddWithKey and PutWithKey.
+	KeyPath string
+	// AutoIncrement generates keys for values that don't have one.
+	AutoIncrement bool
+}
+
+// CreateObjectStore creates an object store. It may only be called
+// during an upgrade.
+func (db *IDBDatabase) CreateObjectStore(name string, opts IDBObjectStoreOptions) (*IDBObjectStore, error) {
+	o := map[string]interface{}{"autoIncrement": opts.AutoIncrement}
+	if opts.KeyPath != "" {
+		o["keyPath"] = opts.KeyPath
+	}
+	s, err := callValueRecover(db.Value, "createObjectStore", name, o)
+	if err != nil {
+		return nil, err
+	}
+	return &IDBObjectStore{s}, nil
+}
+
+// DeleteObjectStore deletes an object store and its data. It may only
+// be called during an upgrade.
+func (db *IDBDatabase) DeleteObjectStore(name string) error {
+	return callRecover(db.Value, "deleteObjectStore", name)
+}
+
+// UpgradeTransaction returns the transaction of the upgrade in
+// progress, which gives access to existing object stores for migrating
+// data. It returns nil outside of the upgrade function passed to Open.
+func (db *IDBDatabase) UpgradeTransaction() *IDBTransaction {
+	if db.upgrade.IsUndefined() {
+		return nil
+	}
+	return &IDBTransaction{db.upgrade}
+}
+
+// View runs fn in a read-only transaction on the named object stores.
+// It returns the error returned by fn, or the error that aborted the
+// transaction.
+func (db *IDBDatabase) View(stores []string, fn func(tx *IDBTransaction) error) error {
+	return db.runTransaction(stores, "readonly", fn)
+}
+
+// Update runs fn in a read-write transaction on the named object
+// stores. If fn returns an error, the transaction is aborted and all
+// of its changes are discarded. Otherwise, Update waits for the
+// transaction to be committed.
+func (db *IDBDatabase) Update(stores []string, fn func(tx *IDBTransaction) error) error {
+	return db.runTransaction(stores, "readwrite", fn)
+}
+
+func (db *IDBDatabase) runTransaction(stores []string, mode string, fn func(tx *IDBTransaction) error) error {
+	names := make([]interface{}, len(stores))
+	for i, s := range stores {
+		names[i] = s
+	}
+	t, err := callValueRecover(db.Value, "transaction", names, mode)
+	if err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	onComplete := js.FuncOf(func(js.Value, []js.Value) interface{} {
+		done <- nil
+		return nil
+	})
+	onAbort := js.FuncOf(func(js.Value, []js.Value) interface{} {
+		if e := t.Get("error"); !e.IsNull() {
+			done <- wrapError(e)
+		} else {
+			done <- ErrAbort
+		}
+		return nil
+	})
+	defer onComplete.Release()
+	defer onAbort.Release()
+	t.Set("oncomplete", onComplete)
+	t.Set("onabort", onAbort)
+
+	if err := fn(&IDBTransaction{t}); err != nil {
+		// Aborting throws if the transaction has already finished.
+		callRecover(t, "abort")
+		<-done
+		return err
+	}
+	if !t.Get("commit").IsUndefined() {
+		// Commit right away instead of waiting for the end of the task.
+		callRecover(t, "commit")
+	}
+	return <-done
+}
+
+// IDBTransaction is a transaction on one or more object stores.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/IDBTransaction.
+type IDBTransaction struct {
+	js.Value
+}
+
+// Mode returns "readonly", "readwrite" or "versionchange".
+func (tx *IDBTransaction) Mode() string { return tx.Get("mode").String() }
+
+func (tx *IDBTransaction) ObjectStoreNames() []string {
+	return domStringListToStrings(tx.Get("objectStoreNames"))
+}
+
+// ObjectStore returns the object store called name. It panics if the
+// store isn't part of the transaction.
+func (tx *IDBTransaction) ObjectStore(name string) *IDBObjectStore {
+	return &IDBObjectStore{tx.Call("objectStore", name)}
+}
+
+// IDBObjectStore is a collection of values, sorted by their keys.
+//
+// Keys are strings, numbers, time.Time values or slices of keys, such
+// as []interface{}, []string or []int. When returned by this package,
+// numbers are float64 and slices are []interface{}.
+//
+// Methods that accept a query take nil, which matches all keys, a key,
+// or an *IDBKeyRange.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/IDBObjectStore.
+type IDBObjectStore struct {
+	js.Value
+}
+
+func (s *IDBObjectStore) Name() string        { return s.Value.Get("name").String() }
+func (s *IDBObjectStore) KeyPath() string     { return toString(s.Value.Get("keyPath")) }
+func (s *IDBObjectStore) AutoIncrement() bool { return s.Value.Get("autoIncrement").Bool() }
+
+func (s *IDBObjectStore) IndexNames() []string {
+	return domStringListToStrings(s.Value.Get("indexNames"))
+}
+
+// Add stores v and returns its key. It fails with ErrConstraint if a
+// value with the same key already exists.
+func (s *IDBObjectStore) Add(v interface{}) (key interface{}, err error) {
+	return s.store("add", v, nil)
+}
+
+// AddWithKey is like Add, for object stores without a key path.
+func (s *IDBObjectStore) AddWithKey(v interface{}, key interface{}) error {
+	_, err := s.store("add", v, key)
+	return err
+}
+
+// Put stores v, replacing any value with the same key, and returns
+// its key.
+func (s *IDBObjectStore) Put(v interface{}) (key interface{}, err error) {
+	return s.store("put", v, nil)
+}
+
+// PutWithKey is like Put, for object stores without a key path.
+func (s *IDBObjectStore) PutWithKey(v interface{}, key interface{}) error {
+	_, err := s.store("put", v, key)
+	return err
+}
+
+func (s *IDBObjectStore) store(method string, v interface{}, key interface{}) (interface{}, error) {
+	o, err := goToJS(v)
+	if err != nil {
+		return nil, err
+	}
+	args := []interface{}{o}
+	if key != nil {
+		args = append(args, idbKeyToJS(key))
+	}
+	r, err := idbRequest(s.Value, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return idbKeyToGo(r), nil
+}
+
+// Get decodes the first value matching query into dst, which must be
+// a pointer. It reports whether there is such a value.
+func (s *IDBObjectStore) Get(query interface{}, dst interface{}) (bool, error) {
+	return idbGet(s.Value, query, dst)
+}
+
+// GetAll decodes all values matching query into dst, which must be a
+// pointer to a slice.
+func (s *IDBObjectStore) GetAll(query interface{}, dst interface{}) error {
+	r, err := idbRequest(s.Value, "getAll", idbQueryToJS(query))
+	if err != nil {
+		return err
+	}
+	return jsToGo(r, dst)
+}
+
+// GetAllKeys returns the keys of all values matching query.
+func (s *IDBObjectStore) GetAllKeys(query interface{}) ([]interface{}, error) {
+	return idbGetAllKeys(s.Value, query)
+}
+
+// Count returns the number of values matching query.
+func (s *IDBObjectStore) Count(query interface{}) (int, error) {
+	return idbCount(s.Value, query)
+}
+
+// Delete deletes all values matching query.
+func (s *IDBObjectStore) Delete(query interface{}) error {
+	_, err := idbRequest(s.Value, "delete", idbQueryToJS(query))
+	return err
+}
+
+// Clear deletes all values.
+func (s *IDBObjectStore) Clear() error {
+	_, err := idbRequest(s.Value, "clear")
+	return err
+}
+
+// IDBIndexOptions are the options for creating an index.
+type IDBIndexOptions struct {
+	// Unique prevents storing two values with the same index key.
+	Unique bool
+	// MultiEntry indexes each element of array index keys separately.
+	MultiEntry bool
+}
+
+// CreateIndex creates an index of the values' keyPath property. It may
+// only be called during an upgrade.
+func (s *IDBObjectStore) CreateIndex(name, keyPath string, opts IDBIndexOptions) (*IDBIndex, error) {
+	i, err := callValueRecover(s.Value, "createIndex", name, keyPath, map[string]interface{}{
+		"unique":     opts.Unique,
+		"multiEntry": opts.MultiEntry,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &IDBIndex{i}, nil
+}
+
+// DeleteIndex deletes an index. It may only be called during an
+// upgrade.
+func (s *IDBObjectStore) DeleteIndex(name string) error {
+	return callRecover(s.Value, "deleteIndex", name)
+}
+
+// Index returns the index called name. It panics if there is no such
+// index.
+func (s *IDBObjectStore) Index(name string) *IDBIndex {
+	return &IDBIndex{s.Call("index", name)}
+}
+
+// OpenCursor opens a cursor over the values matching query.
+func (s *IDBObjectStore) OpenCursor(query interface{}, dir IDBCursorDirection) (*IDBCursor, error) {
+	return openIDBCursor(s.Value, "openCursor", query, dir)
+}
+
+// OpenKeyCursor opens a cursor over the keys matching query. The
+// cursor's Decode method cannot be used.
+func (s *IDBObjectStore) OpenKeyCursor(query interface{}, dir IDBCursorDirection) (*IDBCursor, error) {
+	return openIDBCursor(s.Value, "openKeyCursor", query, dir)
+}
+
+// IDBIndex looks up the values of an object store by the value of one
+// of their properties. Queries refer to index keys, that is the
+// values of that property.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/IDBIndex.
+type IDBIndex struct {
+	js.Value
+}
+
+func (i *IDBIndex) Name() string                 { return i.Value.Get("name").String() }
+func (i *IDBIndex) KeyPath() string              { return toString(i.Value.Get("keyPath")) }
+func (i *IDBIndex) Unique() bool                 { return i.Value.Get("unique").Bool() }
+func (i *IDBIndex) MultiEntry() bool             { return i.Value.Get("multiEntry").Bool() }
+func (i *IDBIndex) ObjectStore() *IDBObjectStore { return &IDBObjectStore{i.Value.Get("objectStore")} }
+
+// Get decodes the first value matching query into dst, which must be
+// a pointer. It reports whether there is such a value.
+func (i *IDBIndex) Get(query interface{}, dst interface{}) (bool, error) {
+	return idbGet(i.Value, query, dst)
+}
+
+// GetKey returns the primary key of the first value matching query,
+// or nil if there is none.
+func (i *IDBIndex) GetKey(query interface{}) (interface{}, error) {
+	r, err := idbRequest(i.Value, "getKey", idbQueryToJS(query))
+	if err != nil {
+		return nil, err
+	}
+	return idbKeyToGo(r), nil
+}
+
+// GetAll decodes all values matching query into dst, which must be a
+// pointer to a slice.
+func (i *IDBIndex) GetAll(query interface{}, dst interface{}) error {
+	r, err := idbRequest(i.Value, "getAll", idbQueryToJS(query))
+	if err != nil {
+		return err
+	}
+	return jsToGo(r, dst)
+}
+
+// GetAllKeys returns the primary keys of all values matching query.
+func (i *IDBIndex) GetAllKeys(query interface{}) ([]interface{}, error) {
+	return idbGetAllKeys(i.Value, query)
+}
+
+// Count returns the number of values matching query.
+func (i *IDBIndex) Count(query interface{}) (int, error) {
+	return idbCount(i.Value, query)
+}
+
+// OpenCursor opens a cursor over the values matching query, in the
+// order of their index keys.
+func (i *IDBIndex) OpenCursor(query interface{}, dir IDBCursorDirection) (*IDBCursor, error) {
+	return openIDBCursor(i.Value, "openCursor", query, dir)
+}
+
+// OpenKeyCursor opens a cursor over the index and primary keys
+// matching query. The cursor's Decode method cannot be used.
+func (i *IDBIndex) OpenKeyCursor(query interface{}, dir IDBCursorDirection) (*IDBCursor, error) {
+	return openIDBCursor(i.Value, "openKeyCursor", query, dir)
+}
+
+// IDBKeyRange is a range of keys, for use in queries. The functions
+// that create key ranges panic if their arguments aren't valid keys or
+// if the lower bound is greater than the upper bound.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/IDBKeyRange.
+type IDBKeyRange struct {
+	js.Value
+}
+
+func idbKeyRange() js.Value { return js.Global().Get("IDBKeyRange") }
+
+// IDBKeyRangeOnly returns a range that only contains key.
+func IDBKeyRangeOnly(key interface{}) *IDBKeyRange {
+	return &IDBKeyRange{idbKeyRange().Call("only", idbKeyToJS(key))}
+}
+
+// IDBKeyRangeLowerBound returns a range of all keys greater than
+// lower, or equal to it if open is false.
+func IDBKeyRangeLowerBound(lower interface{}, open bool) *IDBKeyRange {
+	return &IDBKeyRange{idbKeyRange().Call("lowerBound", idbKeyToJS(lower), open)}
+}
+
+// IDBKeyRangeUpperBound returns a range of all keys less than upper,
+// or equal to it if open is false.
+func IDBKeyRangeUpperBound(upper interface{}, open bool) *IDBKeyRange {
+	return &IDBKeyRange{idbKeyRange().Call("upperBound", idbKeyToJS(upper), open)}
+}
+
+// IDBKeyRangeBound returns a range of all keys between lower and
+// upper. The open arguments exclude the respective bound.
+func IDBKeyRangeBound(lower, upper interface{}, lowerOpen, upperOpen bool) *IDBKeyRange {
+	return &IDBKeyRange{idbKeyRange().Call("bound", idbKeyToJS(lower), idbKeyToJS(upper), lowerOpen, upperOpen)}
+}
+
+func (r *IDBKeyRange) Lower() interface{} { return idbKeyToGo(r.Get("lower")) }
+func (r *IDBKeyRange) Upper() interface{} { return idbKeyToGo(r.Get("upper")) }
+func (r *IDBKeyRange) LowerOpen() bool    { return r.Get("lowerOpen").Bool() }
+func (r *IDBKeyRange) UpperOpen() bool    { return r.Get("upperOpen").Bool() }
+
+// Includes reports whether key is in the range.
+func (r *IDBKeyRange) Includes(key interface{}) bool {
+	return r.Call("includes", idbKeyToJS(key)).Bool()
+}
+
+// IDBCursorDirection is the direction in which a cursor moves.
+type IDBCursorDirection string
+
+const (
+	// IDBCursorNext visits all values in ascending order of their
+	// keys.
+	IDBCursorNext IDBCursorDirection = "next"
+	// IDBCursorNextUnique is like IDBCursorNext, but only visits the
+	// first of several values with the same index key.
+	IDBCursorNextUnique IDBCursorDirection = "nextunique"
+	// IDBCursorPrev visits all values in descending order of their
+	// keys.
+	IDBCursorPrev IDBCursorDirection = "prev"
+	// IDBCursorPrevUnique is like IDBCursorPrev, but only visits the
+	// first of several values with the same index key.
+	IDBCursorPrevUnique IDBCursorDirection = "prevunique"
+)
+
+// IDBCursor iterates over the values of an object store or index.
+// After opening a cursor, and after each call of Continue or Advance,
+// Valid reports whether the cursor points at a value.
+//
+//	c, err := store.OpenCursor(nil, dom.IDBCursorNext)
+//	for err == nil && c.Valid() {
+//		// ...
+//		err = c.Continue()
+//	}
+//
+// Cursors that haven't reached the end must be closed with Close.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/IDBCursor.
+type IDBCursor struct {
+	js.Value // the IDBCursor, or null at the end
+	req      js.Value
+	ch       chan error
+	success  js.Func
+	failure  js.Func
+	closed   bool
+}
+
+func openIDBCursor(source js.Value, method string, query interface{}, dir IDBCursorDirection) (*IDBCursor, error) {
+	if dir == "" {
+		dir = IDBCursorNext
+	}
+	r, err := callValueRecover(source, method, idbQueryToJS(query), string(dir))
+	if err != nil {
+		return nil, err
+	}
+	c := &IDBCursor{req: r, ch: make(chan error, 1)}
+	c.success = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		c.ch <- nil
+		return nil
+	})
+	c.failure = js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		args[0].Call("preventDefault")
+		c.ch <- wrapError(r.Get("error"))
+		return nil
+	})
+	r.Set("onsuccess", c.success)
+	r.Set("onerror", c.failure)
+	if err := c.wait(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *IDBCursor) wait() error {
+	err := <-c.ch
+	if err == nil {
+		c.Value = c.req.Get("result")
+	}
+	if err != nil || !c.Valid() {
+		c.Close()
+	}
+	return err
+}
+
+// Valid reports whether the cursor points at a value.
+func (c *IDBCursor) Valid() bool { return !c.Value.IsNull() }
+
+// Key returns the key at the cursor's position, which is the index
+// key for cursors over indexes.
+func (c *IDBCursor) Key() interface{} { return idbKeyToGo(c.Get("key")) }
+
+// PrimaryKey returns the primary key of the value at the cursor's
+// position.
+func (c *IDBCursor) PrimaryKey() interface{} { return idbKeyToGo(c.Get("primaryKey")) }
+
+// Decode decodes the value at the cursor's position into dst, which
+// must be a pointer.
+func (c *IDBCursor) Decode(dst interface{}) error { return jsToGo(c.Get("value"), dst) }
+
+// Continue moves the cursor to the next value.
+func (c *IDBCursor) Continue() error {
+	if err := callRecover(c.Value, "continue"); err != nil {
+		c.Close()
+		return err
+	}
+	return c.wait()
+}
+
+// Advance moves the cursor forward by n values.
+func (c *IDBCursor) Advance(n int) error {
+	if err := callRecover(c.Value, "advance", n); err != nil {
+		c.Close()
+		return err
+	}
+	return c.wait()
+}
+
+// Update replaces the value at the cursor's position with v.
+func (c *IDBCursor) Update(v interface{}) error {
+	o, err := goToJS(v)
+	if err != nil {
+		return err
+	}
+	_, err = idbRequest(c.Value, "update", o)
+	return err
+}
+
+// Delete deletes the value at the cursor's position.
+func (c *IDBCursor) Delete() error {
+	_, err := idbRequest(c.Value, "delete")
+	return err
+}
+
+// Close releases the resources of the cursor. It is called
+// automatically when the cursor reaches the end or fails.
+func (c *IDBCursor) Close() {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.req.Set("onsuccess", js.Null())
+	c.req.Set("onerror", js.Null())
+	c.success.Release()
+	c.failure.Release()
+}
+
+// idbRequest calls method on o and waits for the resulting IDBRequest
+// to succeed or fail.
+func idbRequest(o js.Value, method string, args ...interface{}) (js.Value, error) {
+	r, err := callValueRecover(o, method, args...)
+	if err != nil {
+		return js.Undefined(), err
+	}
+	return idbWait(r)
+}
+
+// idbWait waits for the IDBRequest r to succeed or fail and returns
+// its result.
+func idbWait(r js.Value) (js.Value, error) {
+	ch := make(chan error, 1)
+	onSuccess := js.FuncOf(func(js.Value, []js.Value) interface{} {
+		ch <- nil
+		return nil
+	})
+	onError := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		// Leave it to the caller whether the error aborts the
+		// transaction.
+		args[0].Call("preventDefault")
+		ch <- wrapError(r.Get("error"))
+		return nil
+	})
+	r.Set("onsuccess", onSuccess)
+	r.Set("onerror", onError)
+	err := <-ch
+	onSuccess.Release()
+	onError.Release()
+	if err != nil {
+		return js.Undefined(), err
+	}
+	return r.Get("result"), nil
+}
+
+func idbGet(o js.Value, query interface{}, dst interface{}) (bool, error) {
+	r, err := idbRequest(o, "get", idbQueryToJS(query))
+	if err != nil || r.IsUndefined() {
+		return false, err
+	}
+	return true, jsToGo(r, dst)
+}
+
+func idbGetAllKeys(o js.Value, query interface{}) ([]interface{}, error) {
+	r, err := idbRequest(o, "getAllKeys", idbQueryToJS(query))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]interface{}, r.Length())
+	for i := range keys {
+		keys[i] = idbKeyToGo(r.Index(i))
+	}
+	return keys, nil
+}
+
+func idbCount(o js.Value, query interface{}) (int, error) {
+	r, err := idbRequest(o, "count", idbQueryToJS(query))
+	if err != nil {
+		return 0, err
+	}
+	return r.Int(), nil
+}
+
+func idbQueryToJS(query interface{}) interface{} {
+	switch q := query.(type) {
+	case nil:
+		return js.Null()
+	case *IDBKeyRange:
+		return q.Value
+	default:
+		return idbKeyToJS(q)
+	}
+}
+
+// idbKeyToJS converts a Go key to JavaScript.
+func idbKeyToJS(key interface{}) interface{} {
+	switch k := key.(type) {
+	case time.Time:
+		return js.Global().Get("Date").New(float64(k.UnixNano()) / float64(time.Millisecond))
+	case []interface{}:
+		out := make([]interface{}, len(k))
+		for i, e := range k {
+			out[i] = idbKeyToJS(e)
+		}
+		return out
+	default:
+		// Typed slices, such as []string or []int, are array keys, too,
+		// but aren't understood by js.ValueOf.
+		if v := reflect.ValueOf(key); v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			out := make([]interface{}, v.Len())
+			for i := range out {
+				out[i] = idbKeyToJS(v.Index(i).Interface())
+			}
+			return out
+		}
+		return k
+	}
+}
+
+// idbKeyToGo converts a JavaScript key to Go. Keys that don't have a Go
+// equivalent, such as binary keys, are returned as js.Value.
+func idbKeyToGo(v js.Value) interface{} {
+	switch v.Type() {
+	case js.TypeUndefined:
+		return nil
+	case js.TypeNumber:
+		return v.Float()
+	case js.TypeString:
+		return v.String()
+	}
+	global := js.Global()
+	if global.Get("Array").Call("isArray", v).Bool() {
+		out := make([]interface{}, v.Length())
+		for i := range out {
+			out[i] = idbKeyToGo(v.Index(i))
+		}
+		return out
+	}
+	if global.Get("Object").Get("prototype").Get("toString").Call("call", v).String() == "[object Date]" {
+		ms := int64(v.Call("getTime").Float())
+		return time.Unix(0, ms*int64(time.Millisecond))
+	}
+	return v
+}
+
+// domStringListToStrings converts a DOMStringList or an array of
+// strings.
+func domStringListToStrings(o js.Value) []string {
+	out := make([]string, o.Length())
+	for i := range out {
+		out[i] = o.Index(i).String()
+	}
+	return out
+}
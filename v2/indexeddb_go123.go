@@ -0,0 +1,49 @@
+//go:build js && go1.23
+// +build js,go1.23
+
+package dom
+
+import (
+	"iter"
+)
+
+// Cursor returns an iterator over a cursor over the values matching
+// query. The cursor is only valid during each iteration. Iteration
+// stops after the first error.
+//
+//	for c, err := range store.Cursor(nil, dom.IDBCursorNext) {
+//		if err != nil {
+//			return err
+//		}
+//		// ...
+//	}
+func (s *IDBObjectStore) Cursor(query interface{}, dir IDBCursorDirection) iter.Seq2[*IDBCursor, error] {
+	return idbCursorSeq(func() (*IDBCursor, error) { return s.OpenCursor(query, dir) })
+}
+
+// Cursor returns an iterator over a cursor over the values matching
+// query, in the order of their index keys. It works like
+// IDBObjectStore.Cursor.
+func (i *IDBIndex) Cursor(query interface{}, dir IDBCursorDirection) iter.Seq2[*IDBCursor, error] {
+	return idbCursorSeq(func() (*IDBCursor, error) { return i.OpenCursor(query, dir) })
+}
+
+func idbCursorSeq(open func() (*IDBCursor, error)) iter.Seq2[*IDBCursor, error] {
+	return func(yield func(*IDBCursor, error) bool) {
+		c, err := open()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer c.Close()
+		for c.Valid() {
+			if !yield(c, nil) {
+				return
+			}
+			if err := c.Continue(); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
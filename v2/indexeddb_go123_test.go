@@ -0,0 +1,76 @@
+//go:build js && go1.23
+// +build js,go1.23
+
+package dom
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestIDBCursorSeq(t *testing.T) {
+	db := openItems(t)
+	err := db.Update([]string{"items"}, func(tx *IDBTransaction) error {
+		s := tx.ObjectStore("items")
+		for _, it := range []idbItem{{2, "two"}, {3, "three"}} {
+			if _, err := s.Put(it); err != nil {
+				return err
+			}
+		}
+
+		var keys []interface{}
+		for c, err := range s.Cursor(nil, IDBCursorNext) {
+			if err != nil {
+				return err
+			}
+			keys = append(keys, c.Key())
+		}
+		if want := []interface{}{1.0, 2.0, 3.0}; !reflect.DeepEqual(keys, want) {
+			t.Errorf("got keys %v, want %v", keys, want)
+		}
+
+		// Breaking out of the loop closes the cursor.
+		var first *IDBCursor
+		for c, err := range s.Cursor(nil, IDBCursorPrev) {
+			if err != nil {
+				return err
+			}
+			first = c
+			break
+		}
+		if first == nil || first.Key() != 3.0 || !first.closed {
+			t.Errorf("got cursor %v, want a closed cursor at key 3", first)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Errors end the iteration.
+	err = db.View([]string{"items"}, func(tx *IDBTransaction) error {
+		var errs []error
+		for _, err := range tx.ObjectStore("items").Cursor(nil, "sideways") {
+			errs = append(errs, err)
+		}
+		if len(errs) != 1 || errs[0] == nil {
+			t.Errorf("got %v for an invalid direction, want one error", errs)
+		}
+
+		errs = nil
+		for c, err := range tx.ObjectStore("items").Cursor(nil, IDBCursorNext) {
+			errs = append(errs, err)
+			if c != nil {
+				tx.Call("abort")
+			}
+		}
+		if len(errs) != 2 || errs[0] != nil || !errors.Is(errs[1], ErrTransactionInactive) {
+			t.Errorf("got %v, want ErrTransactionInactive after the first value", errs)
+		}
+		return nil
+	})
+	if !errors.Is(err, ErrAbort) {
+		t.Errorf("got error %v, want ErrAbort", err)
+	}
+}
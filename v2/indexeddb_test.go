@@ -0,0 +1,498 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"errors"
+	"reflect"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+func TestIDBKeys(t *testing.T) {
+	now := time.Unix(1600000000, 123000000)
+	keys := []interface{}{
+		"key",
+		42.0,
+		now,
+		[]interface{}{"a", 1.0, []interface{}{now}},
+	}
+	for _, k := range keys {
+		got := idbKeyToGo(js.ValueOf(idbKeyToJS(k)))
+		if !reflect.DeepEqual(got, k) {
+			if tm, ok := got.(time.Time); !ok || !tm.Equal(k.(time.Time)) {
+				t.Errorf("got %#v, want %#v", got, k)
+			}
+		}
+	}
+	// Typed slices become array keys.
+	typed := []struct {
+		key  interface{}
+		want []interface{}
+	}{
+		{[]string{"a", "b"}, []interface{}{"a", "b"}},
+		{[]int{1, 2}, []interface{}{1.0, 2.0}},
+		{[][]float64{{0.5}}, []interface{}{[]interface{}{0.5}}},
+		{[2]string{"x", "y"}, []interface{}{"x", "y"}},
+	}
+	for _, tt := range typed {
+		if got := idbKeyToGo(js.ValueOf(idbKeyToJS(tt.key))); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%#v: got %#v, want %#v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestIDBValues(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	o, err := goToJS(item{1, "one"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Get("id").Int() != 1 {
+		t.Errorf("got id %v, want 1", o.Get("id"))
+	}
+	var got item
+	if err := jsToGo(o, &got); err != nil || got != (item{1, "one"}) {
+		t.Errorf("got (%v, %v), want the original item", got, err)
+	}
+}
+
+// fakeIDBRequest returns an object that behaves like an IDBRequest
+// that succeeds with result, or fails with a DOMException called
+// errName if it isn't empty, after a timeout.
+func fakeIDBRequest(result interface{}, errName string) js.Value {
+	return js.Global().Get("Function").New("result", "errName", `
+		const r = {result: undefined, error: null};
+		setTimeout(() => {
+			if (errName) {
+				r.error = new DOMException("failed", errName);
+				r.onerror({preventDefault() {}});
+			} else {
+				r.result = result;
+				r.onsuccess({});
+			}
+		}, 0);
+		return r;
+	`).Invoke(result, errName)
+}
+
+func TestIDBWait(t *testing.T) {
+	if r, err := idbWait(fakeIDBRequest(5, "")); err != nil || r.Int() != 5 {
+		t.Errorf("got (%v, %v), want 5", r, err)
+	}
+	if _, err := idbWait(fakeIDBRequest(nil, "ConstraintError")); !errors.Is(err, ErrConstraint) {
+		t.Errorf("got error %v, want ErrConstraint", err)
+	}
+}
+
+// fakeIndexedDB returns an IDBFactory backed by an in-memory
+// implementation of the parts of IndexedDB that the package uses.
+// Requests settle in later tasks, and transactions commit once they
+// have no outstanding requests at the end of a task, or abort and
+// discard their changes, like in a browser.
+func fakeIndexedDB() *IDBFactory {
+	return &IDBFactory{js.Global().Get("Function").New(`
+		const later = fn => setTimeout(fn, 0);
+		const cmp = (a, b) => a < b ? -1 : a > b ? 1 : 0;
+
+		class Transaction {
+			constructor(db, names, mode) {
+				this.db = db;
+				this.objectStoreNames = names;
+				this.mode = mode;
+				this.error = null;
+				this.pending = 0;
+				this.done = false;
+				this.snapshot = new Map([...db.stores].map(([n, s]) => [n, {...s, data: new Map(s.data)}]));
+				this.check();
+			}
+			objectStore(name) {
+				if (!this.db.stores.has(name)) throw new DOMException("no such store", "NotFoundError");
+				return new Store(this, name);
+			}
+			request(fn) {
+				if (this.done) throw new DOMException("finished", "TransactionInactiveError");
+				const req = {result: undefined, error: null, transaction: this};
+				this.fire(req, fn);
+				return req;
+			}
+			fire(req, fn) {
+				this.pending++;
+				later(() => {
+					this.pending--;
+					if (this.done) return;
+					try {
+						req.result = fn();
+					} catch (e) {
+						req.error = e;
+						let prevented = false;
+						req.onerror && req.onerror({preventDefault() { prevented = true; }});
+						if (prevented) this.check();
+						else this.fail(e);
+						return;
+					}
+					req.onsuccess && req.onsuccess({});
+					this.check();
+				});
+			}
+			check() {
+				later(() => {
+					if (!this.done && this.pending === 0) {
+						this.done = true;
+						this.oncomplete && this.oncomplete({});
+					}
+				});
+			}
+			commit() {
+				if (this.done) throw new DOMException("finished", "InvalidStateError");
+			}
+			abort() {
+				if (this.done) throw new DOMException("finished", "InvalidStateError");
+				this.fail(null);
+			}
+			fail(error) {
+				this.done = true;
+				this.error = error;
+				this.db.stores = this.snapshot;
+				later(() => this.onabort && this.onabort({}));
+			}
+		}
+
+		class Store {
+			constructor(tx, name) {
+				this.tx = tx;
+				this.name = name;
+				this.keyPath = this.s().keyPath;
+				this.autoIncrement = this.s().autoIncrement;
+				this.indexNames = [];
+			}
+			s() { return this.tx.db.stores.get(this.name); }
+			write(v, key, overwrite) {
+				if (this.tx.mode === "readonly") throw new DOMException("read-only", "ReadOnlyError");
+				v = structuredClone(v);
+				return this.tx.request(() => {
+					const s = this.s();
+					if (s.keyPath !== null) key = v[s.keyPath];
+					if (key === undefined && s.autoIncrement) key = s.next++;
+					if (key === undefined) throw new DOMException("no key", "DataError");
+					if (!overwrite && s.data.has(key)) throw new DOMException("key exists", "ConstraintError");
+					s.data.set(key, v);
+					return key;
+				});
+			}
+			add(v, key) { return this.write(v, key, false); }
+			put(v, key) { return this.write(v, key, true); }
+			entries(query) {
+				return [...this.s().data].filter(([k]) => query === null || k === query).sort(([a], [b]) => cmp(a, b));
+			}
+			get(query) { return this.tx.request(() => this.s().data.get(query)); }
+			getAll(query) { return this.tx.request(() => this.entries(query).map(([, v]) => v)); }
+			getAllKeys(query) { return this.tx.request(() => this.entries(query).map(([k]) => k)); }
+			count(query) { return this.tx.request(() => this.entries(query).length); }
+			delete(query) {
+				if (this.tx.mode === "readonly") throw new DOMException("read-only", "ReadOnlyError");
+				return this.tx.request(() => { this.s().data.delete(query); });
+			}
+			openCursor(query, dir) {
+				if (!["next", "nextunique", "prev", "prevunique"].includes(dir)) throw new TypeError("invalid direction");
+				const store = this, tx = this.tx;
+				let list, i = 0;
+				const cursor = {
+					get key() { return list[i][0]; },
+					get primaryKey() { return list[i][0]; },
+					get value() { return list[i][1]; },
+					continue() { this.advance(1); },
+					advance(n) {
+						if (tx.done) throw new DOMException("finished", "TransactionInactiveError");
+						tx.fire(req, () => (i += n) < list.length ? cursor : null);
+					},
+					update(v) { return store.put(v); },
+					delete() { return store.delete(list[i][0]); },
+				};
+				const req = tx.request(() => {
+					list = this.entries(query);
+					if (dir.startsWith("prev")) list.reverse();
+					return list.length ? cursor : null;
+				});
+				return req;
+			}
+		}
+
+		class Database {
+			constructor(name) {
+				this.name = name;
+				this.version = 0;
+				this.stores = new Map();
+				this.upgrade = null;
+			}
+			get objectStoreNames() { return [...this.stores.keys()]; }
+			createObjectStore(name, opts) {
+				if (!this.upgrade) throw new DOMException("not upgrading", "InvalidStateError");
+				if (this.stores.has(name)) throw new DOMException("store exists", "ConstraintError");
+				this.stores.set(name, {keyPath: opts.keyPath ?? null, autoIncrement: opts.autoIncrement, next: 1, data: new Map()});
+				return new Store(this.upgrade, name);
+			}
+			transaction(names, mode) {
+				for (const n of names) {
+					if (!this.stores.has(n)) throw new DOMException("no such store", "NotFoundError");
+				}
+				return new Transaction(this, names, mode);
+			}
+			close() {}
+		}
+
+		const dbs = new Map();
+		return {
+			open(name, version) {
+				const req = {result: undefined, error: null, transaction: null};
+				const fail = (name) => {
+					req.error = new DOMException("failed", name);
+					req.onerror({preventDefault() {}});
+				};
+				later(() => {
+					const db = dbs.get(name) || new Database(name);
+					const old = db.version;
+					if (version === undefined) version = old || 1;
+					if (version < old) return fail("VersionError");
+					req.result = db;
+					if (version === old) return req.onsuccess({});
+					const tx = new Transaction(db, db.objectStoreNames, "versionchange");
+					db.upgrade = tx;
+					db.version = version;
+					req.transaction = tx;
+					tx.oncomplete = () => {
+						db.upgrade = null;
+						dbs.set(name, db);
+						req.transaction = null;
+						req.onsuccess({});
+					};
+					tx.onabort = () => {
+						db.upgrade = null;
+						db.version = old;
+						req.result = undefined;
+						fail("AbortError");
+					};
+					req.onupgradeneeded({oldVersion: old, newVersion: version});
+				});
+				return req;
+			},
+			deleteDatabase(name) {
+				const req = {result: undefined, error: null};
+				later(() => {
+					dbs.delete(name);
+					req.onsuccess({});
+				});
+				return req;
+			},
+		};
+	`).Invoke()}
+}
+
+type idbItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// openItems opens a fake database with an object store called items,
+// which contains the item with ID 1.
+func openItems(t *testing.T) *IDBDatabase {
+	t.Helper()
+	db, err := fakeIndexedDB().Open("test", 1, func(db *IDBDatabase, oldVersion, newVersion int) error {
+		s, err := db.CreateObjectStore("items", IDBObjectStoreOptions{KeyPath: "id"})
+		if err != nil {
+			return err
+		}
+		_, err = s.Add(idbItem{1, "one"})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestIDBOpen(t *testing.T) {
+	f := fakeIndexedDB()
+	var versions [2]int
+	db, err := f.Open("test", 2, func(db *IDBDatabase, oldVersion, newVersion int) error {
+		versions = [2]int{oldVersion, newVersion}
+		if tx := db.UpgradeTransaction(); tx == nil || tx.Mode() != "versionchange" {
+			t.Errorf("got upgrade transaction %v, want a versionchange transaction", tx)
+		}
+		s, err := db.CreateObjectStore("items", IDBObjectStoreOptions{KeyPath: "id"})
+		if err != nil {
+			return err
+		}
+		_, err = s.Put(idbItem{1, "one"})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versions != [2]int{0, 2} {
+		t.Errorf("got versions %v, want [0 2]", versions)
+	}
+	if db.Version() != 2 || !reflect.DeepEqual(db.ObjectStoreNames(), []string{"items"}) {
+		t.Errorf("got version %d and stores %q", db.Version(), db.ObjectStoreNames())
+	}
+	if db.UpgradeTransaction() != nil {
+		t.Error("got an upgrade transaction after Open returned")
+	}
+
+	// A failed upgrade is aborted and leaves the database unchanged.
+	errUpgrade := errors.New("upgrade failed")
+	_, err = f.Open("test", 3, func(db *IDBDatabase, oldVersion, newVersion int) error {
+		if _, err := db.CreateObjectStore("other", IDBObjectStoreOptions{AutoIncrement: true}); err != nil {
+			return err
+		}
+		return errUpgrade
+	})
+	if err != errUpgrade {
+		t.Errorf("got error %v, want the upgrade's error", err)
+	}
+	db, err = f.Open("test", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Version() != 2 || len(db.ObjectStoreNames()) != 1 {
+		t.Errorf("got version %d and stores %q after a failed upgrade", db.Version(), db.ObjectStoreNames())
+	}
+	if _, err := f.Open("test", 1, nil); !errors.Is(err, ErrVersion) {
+		t.Errorf("got error %v, want ErrVersion", err)
+	}
+}
+
+func TestIDBTransactions(t *testing.T) {
+	db := openItems(t)
+	items := []string{"items"}
+
+	err := db.Update(items, func(tx *IDBTransaction) error {
+		s := tx.ObjectStore("items")
+		if _, err := s.Put(idbItem{2, "two"}); err != nil {
+			return err
+		}
+		_, err := s.Put(idbItem{3, "three"})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Returning an error aborts the transaction and discards its
+	// changes.
+	errStop := errors.New("stop")
+	err = db.Update(items, func(tx *IDBTransaction) error {
+		if err := tx.ObjectStore("items").Delete(1.0); err != nil {
+			return err
+		}
+		return errStop
+	})
+	if err != errStop {
+		t.Errorf("got error %v, want the function's error", err)
+	}
+	// So does a failed request whose error is returned.
+	err = db.Update(items, func(tx *IDBTransaction) error {
+		_, err := tx.ObjectStore("items").Add(idbItem{2, "again"})
+		return err
+	})
+	if !errors.Is(err, ErrConstraint) {
+		t.Errorf("got error %v, want ErrConstraint", err)
+	}
+	// Aborting the transaction directly is reported as ErrAbort.
+	err = db.Update(items, func(tx *IDBTransaction) error {
+		if _, err := tx.ObjectStore("items").Put(idbItem{4, "four"}); err != nil {
+			return err
+		}
+		tx.Call("abort")
+		return nil
+	})
+	if !errors.Is(err, ErrAbort) {
+		t.Errorf("got error %v, want ErrAbort", err)
+	}
+
+	err = db.View(items, func(tx *IDBTransaction) error {
+		s := tx.ObjectStore("items")
+		var all []idbItem
+		if err := s.GetAll(nil, &all); err != nil {
+			return err
+		}
+		want := []idbItem{{1, "one"}, {2, "two"}, {3, "three"}}
+		if !reflect.DeepEqual(all, want) {
+			t.Errorf("got %v, want %v", all, want)
+		}
+		var it idbItem
+		if ok, err := s.Get(2, &it); err != nil || !ok || it.Name != "two" {
+			t.Errorf("got (%v, %t, %v), want item two", it, ok, err)
+		}
+		if ok, err := s.Get(5, &it); err != nil || ok {
+			t.Errorf("got (%t, %v) for a missing key", ok, err)
+		}
+		if _, err := s.Put(idbItem{5, "five"}); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("got error %v, want ErrReadOnly", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.View([]string{"missing"}, func(*IDBTransaction) error { return nil }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want ErrNotFound", err)
+	}
+}
+
+func TestIDBCursor(t *testing.T) {
+	db := openItems(t)
+	err := db.Update([]string{"items"}, func(tx *IDBTransaction) error {
+		s := tx.ObjectStore("items")
+		for _, it := range []idbItem{{2, "two"}, {3, "three"}} {
+			if _, err := s.Put(it); err != nil {
+				return err
+			}
+		}
+
+		var names []string
+		c, err := s.OpenCursor(nil, IDBCursorPrev)
+		for err == nil && c.Valid() {
+			var it idbItem
+			if err := c.Decode(&it); err != nil {
+				return err
+			}
+			names = append(names, it.Name)
+			if c.PrimaryKey() == 2.0 {
+				if err := c.Update(idbItem{2, "TWO"}); err != nil {
+					return err
+				}
+			}
+			err = c.Continue()
+		}
+		if err != nil {
+			return err
+		}
+		if want := []string{"three", "two", "one"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("got %q, want %q", names, want)
+		}
+
+		c, err = s.OpenCursor(nil, "")
+		if err != nil {
+			return err
+		}
+		if err := c.Advance(2); err != nil || !c.Valid() || c.Key() != 3.0 {
+			t.Errorf("got (%v, %v) after advancing, want key 3", c.Key(), err)
+		}
+		c.Close()
+
+		var it idbItem
+		if _, err := s.Get(2, &it); err != nil || it.Name != "TWO" {
+			t.Errorf("got (%v, %v), want the updated item", it, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
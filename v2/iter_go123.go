@@ -0,0 +1,63 @@
+//go:build js && go1.23
+// +build js,go1.23
+
+package dom
+
+import (
+	"iter"
+)
+
+// All returns an iterator over the nodes following the walker's
+// current node, in document order. Iterating moves the walker.
+func (w *TreeWalker) All() iter.Seq[Node] {
+	return func(yield func(Node) bool) {
+		for n := w.NextNode(); n != nil; n = w.NextNode() {
+			if !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the remaining nodes of the iterator.
+func (it *NodeIterator) All() iter.Seq[Node] {
+	return func(yield func(Node) bool) {
+		for n := it.NextNode(); n != nil; n = it.NextNode() {
+			if !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the nodes in the list. For live lists,
+// the length is checked anew before each step, so nodes removed from
+// the DOM during iteration may cause other nodes to be skipped.
+func (l *NodeList) All() iter.Seq[Node] {
+	return func(yield func(Node) bool) {
+		for i := 0; i < l.Len(); i++ {
+			if !yield(l.Item(i)) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the elements in the collection. The
+// length is checked anew before each step, so elements removed from
+// the DOM during iteration may cause other elements to be skipped.
+func (c *HTMLCollection) All() iter.Seq[Element] {
+	return func(yield func(Element) bool) {
+		for i := 0; i < c.Len(); i++ {
+			if !yield(c.Item(i)) {
+				return
+			}
+		}
+	}
+}
+
+// ChildNodesSeq returns an iterator over the node's children. Unlike
+// ChildNodes, it doesn't create a snapshot of all children up front.
+func (n *BasicNode) ChildNodesSeq() iter.Seq[Node] {
+	return n.ChildNodesLive().All()
+}
@@ -0,0 +1,203 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"context"
+	"sync"
+	"syscall/js"
+)
+
+// structuredValue converts v to a value that can be sent with
+// postMessage. A byte slice becomes a Uint8Array, and all other values
+// are converted with structuredElement.
+func structuredValue(v interface{}) (interface{}, error) {
+	if b, ok := v.([]byte); ok {
+		a := js.Global().Get("Uint8Array").New(len(b))
+		js.CopyBytesToJS(a, b)
+		return a, nil
+	}
+	return structuredElement(v)
+}
+
+// structuredElement converts v to a value that can be sent with
+// postMessage. Values supported by js.ValueOf are passed as is,
+// MessagePorts become their underlying object, and all other values
+// are converted as with encoding/json. The elements of a
+// map[string]interface{} or []interface{} are converted the same way,
+// so that they may contain values that js.ValueOf doesn't support.
+// Byte slices within them are encoded as JSON, as they are within
+// structs, so that jsToGo can decode them again.
+func structuredElement(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil, bool, string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, js.Value, js.Func:
+		return v, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			c, err := structuredElement(e)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = c
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			c, err := structuredElement(e)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = c
+		}
+		return out, nil
+	case *MessagePort:
+		return v.Value, nil
+	default:
+		return goToJS(v)
+	}
+}
+
+// transferList converts the objects to be transferred with
+// postMessage, which may be js.Values or MessagePorts.
+func transferList(transfer []interface{}) []interface{} {
+	out := make([]interface{}, len(transfer))
+	for i, t := range transfer {
+		if p, ok := t.(*MessagePort); ok {
+			out[i] = p.Value
+		} else {
+			out[i] = t
+		}
+	}
+	return out
+}
+
+// postMessage calls the postMessage method of o with message and any
+// further arguments, followed by the transfer list.
+func postMessage(o js.Value, message interface{}, transfer []interface{}, args ...interface{}) error {
+	v, err := structuredValue(message)
+	if err != nil {
+		return err
+	}
+	args = append([]interface{}{v}, args...)
+	if transfer != nil {
+		args = append(args, transferList(transfer))
+	}
+	return callRecover(o, "postMessage", args...)
+}
+
+// receiveMessages returns a channel of the message events dispatched
+// at target. Events are buffered until they are received from the
+// channel. The channel is closed, and the listener removed, when ctx is
+// done.
+func receiveMessages(ctx context.Context, target js.Value) <-chan *MessageEvent {
+	out := make(chan *MessageEvent)
+	var (
+		mu     sync.Mutex
+		queue  []*MessageEvent
+		notify = make(chan struct{}, 1)
+	)
+	fn := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		mu.Lock()
+		queue = append(queue, &MessageEvent{BasicEvent: &BasicEvent{args[0]}})
+		mu.Unlock()
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	target.Call("addEventListener", "message", fn)
+	go func() {
+		defer func() {
+			target.Call("removeEventListener", "message", fn)
+			fn.Release()
+			close(out)
+		}()
+		for {
+			mu.Lock()
+			var next *MessageEvent
+			if len(queue) > 0 {
+				next = queue[0]
+				queue = queue[1:]
+			}
+			mu.Unlock()
+			if next == nil {
+				select {
+				case <-notify:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case out <- next:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// MessageChannel is a pair of connected MessagePorts. Messages posted
+// to one port are received by the other.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/MessageChannel.
+type MessageChannel struct {
+	js.Value
+}
+
+func NewMessageChannel() *MessageChannel {
+	return &MessageChannel{js.Global().Get("MessageChannel").New()}
+}
+
+func (c *MessageChannel) Port1() *MessagePort { return &MessagePort{&BasicEventTarget{c.Get("port1")}} }
+func (c *MessageChannel) Port2() *MessagePort { return &MessagePort{&BasicEventTarget{c.Get("port2")}} }
+
+// MessagePort is one end of a MessageChannel. Ports can be transferred
+// to other windows and workers by including them in the transfer list
+// of PostMessage.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/MessagePort.
+type MessagePort struct {
+	*BasicEventTarget
+}
+
+// PostMessage sends a copy of message to the other port, transferring
+// the objects in transfer. message is converted like for
+// Window.PostMessage. It returns ErrDataClone if message cannot be
+// copied.
+func (p *MessagePort) PostMessage(message interface{}, transfer []interface{}) error {
+	return postMessage(p.Value, message, transfer)
+}
+
+// Start starts delivering messages. Messages are queued until then,
+// unless they are received with Messages, which starts the port
+// itself.
+func (p *MessagePort) Start() { p.Call("start") }
+
+// Close disconnects the port.
+func (p *MessagePort) Close() { p.Call("close") }
+
+// Messages starts the port and returns a channel of the messages it
+// receives. Messages are buffered until they are received from the
+// channel. The channel is closed when ctx is done.
+func (p *MessagePort) Messages(ctx context.Context) <-chan *MessageEvent {
+	ch := receiveMessages(ctx, p.Value)
+	p.Start()
+	return ch
+}
+
+// addMessageListener adds fn as a listener for message events at t.
+func addMessageListener(t *BasicEventTarget, fn func(*MessageEvent)) js.Func {
+	f := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		fn(&MessageEvent{BasicEvent: &BasicEvent{args[0]}})
+		return nil
+	})
+	t.Call("addEventListener", "message", f)
+	return f
+}
@@ -0,0 +1,100 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+func TestMessageChannel(t *testing.T) {
+	if js.Global().Get("MessageChannel").IsUndefined() {
+		t.Skip("MessageChannel is not available")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c := NewMessageChannel()
+	defer c.Port1().Close()
+	msgs := c.Port2().Messages(ctx)
+
+	type point struct{ X, Y int }
+	if err := c.Port1().PostMessage(point{1, 2}, nil); err != nil {
+		t.Fatal(err)
+	}
+	other := NewMessageChannel()
+	if err := c.Port1().PostMessage([]byte("bytes"), []interface{}{other.Port1()}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ev := <-msgs:
+		var p point
+		if err := ev.DecodeData(&p); err != nil || p != (point{1, 2}) {
+			t.Errorf("got (%v, %v), want {1 2}", p, err)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+	select {
+	case ev := <-msgs:
+		if got := ev.Data().Get("byteLength").Int(); got != 5 {
+			t.Errorf("got %d bytes, want 5", got)
+		}
+		if n := len(ev.Ports()); n != 1 {
+			t.Errorf("got %d ports, want 1", n)
+		}
+		ev.Ports()[0].Close()
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+	other.Port2().Close()
+	c.Port2().Close()
+
+	cancel()
+	if _, ok := <-msgs; ok {
+		t.Error("channel is still open after cancelling the context")
+	}
+}
+
+func TestStructuredValue(t *testing.T) {
+	type point struct{ X, Y int }
+	v, err := structuredValue(map[string]interface{}{
+		"bytes":  []byte("hi"),
+		"points": []interface{}{point{1, 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := js.ValueOf(v)
+	if x := o.Get("points").Index(0).Get("X"); x.Type() != js.TypeNumber || x.Int() != 1 {
+		t.Errorf("nested struct became %v", o.Get("points").Index(0))
+	}
+
+	// Decoding reverses the conversion of []byte, at the top level and
+	// nested in maps and structs.
+	var m struct {
+		Bytes  []byte `json:"bytes"`
+		Points []point
+	}
+	if err := jsToGo(o, &m); err != nil || string(m.Bytes) != "hi" || len(m.Points) != 1 || m.Points[0] != (point{1, 2}) {
+		t.Errorf("got (%+v, %v), want the original map", m, err)
+	}
+	v, _ = structuredValue([]byte("hi"))
+	if !js.ValueOf(v).InstanceOf(js.Global().Get("Uint8Array")) {
+		t.Errorf("[]byte became %v, want a Uint8Array", v)
+	}
+	var b []byte
+	if err := jsToGo(js.ValueOf(v), &b); err != nil || string(b) != "hi" {
+		t.Errorf("got (%q, %v), want hi", b, err)
+	}
+	type blob struct{ Data []byte }
+	v, _ = structuredValue(blob{[]byte("hi")})
+	var got blob
+	if err := jsToGo(js.ValueOf(v), &got); err != nil || string(got.Data) != "hi" {
+		t.Errorf("got (%q, %v), want hi", got.Data, err)
+	}
+}
@@ -0,0 +1,125 @@
+//go:build js && go1.18
+// +build js,go1.18
+
+package dom
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Querier is implemented by the nodes that support selector queries:
+// Document, DocumentFragment and Element.
+type Querier interface {
+	QuerySelectorErr(sel string) (Element, error)
+	QuerySelectorAllErr(sel string) ([]Element, error)
+}
+
+// ErrNoMatch is returned, wrapped in a more descriptive error, by
+// QuerySelectorAs, GetElementByIDAs and ClosestAs when no element
+// matches.
+var ErrNoMatch = errors.New("no matching element")
+
+// ElementTypeError is returned by QuerySelectorAs, QuerySelectorAllAs,
+// GetElementByIDAs and ClosestAs when a matching element doesn't have
+// the requested type.
+type ElementTypeError struct {
+	// Query describes the lookup, such as `QuerySelector("#name")`.
+	Query string
+	// Element is the element that was found.
+	Element Element
+	// Want is the requested type.
+	Want reflect.Type
+}
+
+func (e *ElementTypeError) Error() string {
+	return fmt.Sprintf("dom: %s: got %T, want %s", e.Query, e.Element, e.Want)
+}
+
+func asElement[T Element](el Element, query string) (T, error) {
+	var zero T
+	if el == nil {
+		return zero, fmt.Errorf("dom: %s: %w", query, ErrNoMatch)
+	}
+	t, ok := el.(T)
+	if !ok {
+		return zero, &ElementTypeError{Query: query, Element: el, Want: reflect.TypeOf(&zero).Elem()}
+	}
+	return t, nil
+}
+
+// QuerySelectorAs returns the first element in root's subtree that
+// matches sel. It returns an error if sel is invalid, if no element
+// matches, or if the element isn't of type T.
+//
+//	input, err := dom.QuerySelectorAs[*dom.HTMLInputElement](doc, "#name")
+func QuerySelectorAs[T Element](root Querier, sel string) (T, error) {
+	el, err := root.QuerySelectorErr(sel)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return asElement[T](el, fmt.Sprintf("QuerySelector(%q)", sel))
+}
+
+// QuerySelectorAllAs returns all elements in root's subtree that match
+// sel. It returns an error if sel is invalid or if any of the elements
+// isn't of type T. No matches aren't an error.
+func QuerySelectorAllAs[T Element](root Querier, sel string) ([]T, error) {
+	els, err := root.QuerySelectorAllErr(sel)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, len(els))
+	for i, el := range els {
+		t, ok := el.(T)
+		if !ok {
+			var zero T
+			return nil, &ElementTypeError{
+				Query:   fmt.Sprintf("QuerySelectorAll(%q)", sel),
+				Element: el,
+				Want:    reflect.TypeOf(&zero).Elem(),
+			}
+		}
+		out[i] = t
+	}
+	return out, nil
+}
+
+// GetElementByIDAs returns the element in root's subtree whose id is
+// id. It returns an error if there is no such element or if it isn't of
+// type T.
+//
+// Documents and document fragments look up the element by ID.
+// Elements, which don't support that, use an attribute selector
+// instead.
+func GetElementByIDAs[T Element](root Querier, id string) (T, error) {
+	query := fmt.Sprintf("GetElementByID(%q)", id)
+	if r, ok := root.(interface{ GetElementByID(string) Element }); ok {
+		return asElement[T](r.GetElementByID(id), query)
+	}
+	el, err := root.QuerySelectorErr(`[id="` + cssStringEscaper.Replace(id) + `"]`)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return asElement[T](el, query)
+}
+
+// cssStringEscaper escapes the characters that cannot appear verbatim
+// in a double-quoted CSS string.
+var cssStringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\a `, "\r", `\d `, "\f", `\c `, "\x00", `\fffd `)
+
+// ClosestAs returns the closest ancestor of el, including el itself,
+// that matches sel. It returns an error if sel is invalid, if no
+// element matches, or if the element isn't of type T.
+func ClosestAs[T Element](el Element, sel string) (T, error) {
+	c, err := el.ClosestErr(sel)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return asElement[T](c, fmt.Sprintf("Closest(%q)", sel))
+}
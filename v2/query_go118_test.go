@@ -0,0 +1,125 @@
+//go:build js && go1.18
+// +build js,go1.18
+
+package dom
+
+import (
+	"errors"
+	"syscall/js"
+	"testing"
+)
+
+type fakeQuerier []Element
+
+func (q fakeQuerier) QuerySelectorErr(string) (Element, error) {
+	if len(q) == 0 {
+		return nil, nil
+	}
+	return q[0], nil
+}
+
+func (q fakeQuerier) QuerySelectorAllErr(string) ([]Element, error) { return q, nil }
+
+func TestQuerySelectorAs(t *testing.T) {
+	input := wrapHTMLElement(js.Global().Get("HTMLInputElement").New())
+	div := wrapHTMLElement(js.Global().Get("HTMLDivElement").New())
+
+	if el, err := QuerySelectorAs[*HTMLInputElement](fakeQuerier{input}, "input"); err != nil || el != input {
+		t.Errorf("got (%v, %v), want the input element", el, err)
+	}
+	if _, err := QuerySelectorAs[*HTMLInputElement](fakeQuerier{}, "input"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("got error %v, want ErrNoMatch", err)
+	}
+	var typeErr *ElementTypeError
+	if _, err := QuerySelectorAs[*HTMLInputElement](fakeQuerier{div}, "div"); !errors.As(err, &typeErr) || typeErr.Element != div {
+		t.Errorf("got error %v, want ElementTypeError", err)
+	}
+	if _, err := QuerySelectorAllAs[*HTMLInputElement](fakeQuerier{input, div}, "*"); !errors.As(err, &typeErr) {
+		t.Errorf("got error %v, want ElementTypeError", err)
+	}
+	if els, err := QuerySelectorAllAs[HTMLElement](fakeQuerier{input, div}, "*"); err != nil || len(els) != 2 {
+		t.Errorf("got (%v, %v), want two elements", els, err)
+	}
+}
+
+// recordingQuerier records the selector of the last query.
+type recordingQuerier struct {
+	sel string
+	el  Element
+}
+
+func (q *recordingQuerier) QuerySelectorErr(sel string) (Element, error) {
+	q.sel = sel
+	return q.el, nil
+}
+
+func (q *recordingQuerier) QuerySelectorAllErr(sel string) ([]Element, error) {
+	q.sel = sel
+	return []Element{q.el}, nil
+}
+
+// idQuerier is a Querier that supports lookups by ID, like documents.
+type idQuerier struct {
+	fakeQuerier
+	ids map[string]Element
+}
+
+func (q idQuerier) GetElementByID(id string) Element { return q.ids[id] }
+
+func TestGetElementByIDAs(t *testing.T) {
+	input := wrapHTMLElement(js.Global().Get("HTMLInputElement").New())
+	div := wrapHTMLElement(js.Global().Get("HTMLDivElement").New())
+
+	tests := []struct {
+		id, sel string
+	}{
+		{"name", `[id="name"]`},
+		{`say "hi"`, `[id="say \"hi\""]`},
+		{`a\b`, `[id="a\\b"]`},
+		{"two\nlines\r\f", `[id="two\a lines\d \c "]`},
+		{"nul\x00", `[id="nul\fffd "]`},
+	}
+	for _, tt := range tests {
+		q := &recordingQuerier{el: input}
+		if el, err := GetElementByIDAs[*HTMLInputElement](q, tt.id); err != nil || el != input {
+			t.Errorf("%q: got (%v, %v), want the input element", tt.id, el, err)
+		}
+		if q.sel != tt.sel {
+			t.Errorf("%q: got selector %s, want %s", tt.id, q.sel, tt.sel)
+		}
+	}
+	if _, err := GetElementByIDAs[*HTMLInputElement](&recordingQuerier{}, "name"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("got error %v, want ErrNoMatch", err)
+	}
+
+	doc := idQuerier{ids: map[string]Element{"name": input, "box": div}}
+	if el, err := GetElementByIDAs[*HTMLInputElement](doc, "name"); err != nil || el != input {
+		t.Errorf("got (%v, %v), want the input element", el, err)
+	}
+	if _, err := GetElementByIDAs[*HTMLInputElement](doc, "missing"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("got error %v, want ErrNoMatch", err)
+	}
+	var typeErr *ElementTypeError
+	if _, err := GetElementByIDAs[*HTMLInputElement](doc, "box"); !errors.As(err, &typeErr) || typeErr.Element != div {
+		t.Errorf("got error %v, want ElementTypeError", err)
+	}
+}
+
+func TestClosestAs(t *testing.T) {
+	form := js.Global().Get("HTMLFormElement").New()
+	el := wrapHTMLElement(js.Global().Get("HTMLInputElement").New())
+	el.Underlying().Set("closest", js.Global().Get("Function").New("form", `
+		return sel => sel === "form" || sel === "*" ? form : null;
+	`).Invoke(form))
+
+	if f, err := ClosestAs[*HTMLFormElement](el, "form"); err != nil || !f.Underlying().Equal(form) {
+		t.Errorf("got (%v, %v), want the form", f, err)
+	}
+	if _, err := ClosestAs[*HTMLFormElement](el, "table"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("got error %v, want ErrNoMatch", err)
+	}
+	var typeErr *ElementTypeError
+	if _, err := ClosestAs[*HTMLDivElement](el, "*"); !errors.As(err, &typeErr) {
+		t.Errorf("got error %v, want ElementTypeError", err)
+	}
+}
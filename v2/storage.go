@@ -0,0 +1,56 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"syscall/js"
+)
+
+// Storage is a string key-value store that persists across page loads
+// (local storage) or for the lifetime of a tab (session storage). It is
+// shared between all documents of the same origin.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/Storage.
+type Storage struct {
+	js.Value
+}
+
+// Length returns the number of items.
+func (s *Storage) Length() int { return s.Get("length").Int() }
+
+// Key returns the key of the i-th item. ok is false if i is out of
+// range. The order of keys is defined by the browser and may change
+// when items are added or removed.
+func (s *Storage) Key(i int) (key string, ok bool) {
+	v := s.Call("key", i)
+	if v.IsNull() {
+		return "", false
+	}
+	return v.String(), true
+}
+
+// GetItem returns the value stored under key and whether it exists.
+func (s *Storage) GetItem(key string) (value string, ok bool) {
+	v := s.Call("getItem", key)
+	if v.IsNull() {
+		return "", false
+	}
+	return v.String(), true
+}
+
+// SetItem stores value under key. It returns ErrQuotaExceeded if the
+// storage is full or disabled.
+func (s *Storage) SetItem(key, value string) error {
+	return callRecover(s.Value, "setItem", key, value)
+}
+
+func (s *Storage) RemoveItem(key string) { s.Call("removeItem", key) }
+func (s *Storage) Clear()                { s.Call("clear") }
+
+func wrapStorage(o js.Value) *Storage {
+	if o.IsNull() || o.IsUndefined() {
+		return nil
+	}
+	return &Storage{o}
+}
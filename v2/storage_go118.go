@@ -0,0 +1,28 @@
+//go:build js && go1.18
+// +build js,go1.18
+
+package dom
+
+import (
+	"encoding/json"
+)
+
+// StorageGet decodes the JSON value stored under key into a T. ok is
+// false if there is no such item.
+func StorageGet[T any](s *Storage, key string) (v T, ok bool, err error) {
+	data, ok := s.GetItem(key)
+	if !ok {
+		return v, false, nil
+	}
+	err = json.Unmarshal([]byte(data), &v)
+	return v, true, err
+}
+
+// StorageSet stores v under key, encoded as JSON.
+func StorageSet[T any](s *Storage, key string, v T) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.SetItem(key, string(data))
+}
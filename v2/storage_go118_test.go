@@ -0,0 +1,29 @@
+//go:build js && go1.18
+// +build js,go1.18
+
+package dom
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStorageGetSet(t *testing.T) {
+	s := fakeStorage(32)
+	type settings struct {
+		Theme string
+		Size  int
+	}
+	if _, ok, err := StorageGet[settings](s, "settings"); ok || err != nil {
+		t.Errorf("got (%t, %v) for missing item, want (false, nil)", ok, err)
+	}
+	if err := StorageSet(s, "settings", settings{"dark", 12}); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok, err := StorageGet[settings](s, "settings"); !ok || err != nil || v != (settings{"dark", 12}) {
+		t.Errorf("got (%v, %t, %v), want the stored settings", v, ok, err)
+	}
+	if err := StorageSet(s, "big", "0123456789012345678901234567890123456789"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("got error %v, want ErrQuotaExceeded", err)
+	}
+}
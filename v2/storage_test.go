@@ -0,0 +1,81 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"errors"
+	"syscall/js"
+	"testing"
+)
+
+// fakeStorage returns a minimal implementation of the Storage
+// interface that throws a QuotaExceededError for values longer than
+// quota.
+func fakeStorage(quota int) *Storage {
+	return &Storage{js.Global().Get("Function").New("quota", `
+		const m = new Map();
+		return {
+			get length() { return m.size; },
+			key(i) { return i < m.size ? [...m.keys()][i] : null; },
+			getItem(k) { return m.has(k) ? m.get(k) : null; },
+			setItem(k, v) {
+				if (String(v).length > quota) throw new DOMException("full", "QuotaExceededError");
+				m.set(k, String(v));
+			},
+			removeItem(k) { m.delete(k); },
+			clear() { m.clear(); },
+		};
+	`).Invoke(quota)}
+}
+
+func TestStorage(t *testing.T) {
+	s := fakeStorage(32)
+	if _, ok := s.GetItem("k"); ok {
+		t.Error("got a missing item")
+	}
+	if err := s.SetItem("k", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := s.GetItem("k"); !ok || v != "v" {
+		t.Errorf("got (%q, %t), want (v, true)", v, ok)
+	}
+	if k, ok := s.Key(0); !ok || k != "k" || s.Length() != 1 {
+		t.Errorf("got key %q, %t, want k", k, ok)
+	}
+	if _, ok := s.Key(1); ok {
+		t.Error("got a key past the end")
+	}
+	if err := s.SetItem("big", "0123456789012345678901234567890123456789"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("got error %v, want ErrQuotaExceeded", err)
+	}
+	s.RemoveItem("k")
+	if s.Length() != 0 {
+		t.Errorf("got length %d after RemoveItem, want 0", s.Length())
+	}
+	s.SetItem("k", "v")
+	s.Clear()
+	if _, ok := s.GetItem("k"); ok {
+		t.Error("item still exists after Clear")
+	}
+}
+
+func TestLocalStorageErr(t *testing.T) {
+	obj := js.Global().Get("Object").New()
+	throwingAccessor(obj, "localStorage", "SecurityError")
+	throwingAccessor(obj, "sessionStorage", "SecurityError")
+	w := &window{obj}
+	if _, err := w.LocalStorageErr(); !errors.Is(err, ErrSecurity) {
+		t.Errorf("got %v, want ErrSecurity", err)
+	}
+	if _, err := w.SessionStorageErr(); !errors.Is(err, ErrSecurity) {
+		t.Errorf("got %v, want ErrSecurity", err)
+	}
+
+	js.Global().Get("Object").Call("defineProperty", obj, "localStorage", map[string]interface{}{
+		"value": fakeStorage(32).Value,
+	})
+	if s, err := w.LocalStorageErr(); err != nil || s == nil {
+		t.Errorf("got (%v, %v), want the storage", s, err)
+	}
+}
@@ -0,0 +1,123 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"syscall/js"
+)
+
+// Values for the whatToShow argument of Document.CreateTreeWalker and
+// Document.CreateNodeIterator. They can be combined with bitwise OR.
+const (
+	ShowAll                   uint32 = 0xFFFFFFFF
+	ShowElement               uint32 = 0x1
+	ShowAttribute             uint32 = 0x2
+	ShowText                  uint32 = 0x4
+	ShowCDATASection          uint32 = 0x8
+	ShowProcessingInstruction uint32 = 0x40
+	ShowComment               uint32 = 0x80
+	ShowDocument              uint32 = 0x100
+	ShowDocumentType          uint32 = 0x200
+	ShowDocumentFragment      uint32 = 0x400
+)
+
+// FilterResult is the result of a NodeFilter.
+type FilterResult int
+
+const (
+	// FilterAccept accepts the node.
+	FilterAccept FilterResult = 1
+	// FilterReject rejects the node. A TreeWalker also skips the
+	// node's descendants, whereas a NodeIterator treats it like
+	// FilterSkip.
+	FilterReject FilterResult = 2
+	// FilterSkip skips the node, but not its descendants.
+	FilterSkip FilterResult = 3
+)
+
+// NodeFilter decides whether a TreeWalker or NodeIterator returns a
+// node. It is only called for nodes that match whatToShow.
+type NodeFilter func(Node) FilterResult
+
+// jsFilter returns the JavaScript function to pass for filter, which
+// is null if filter is nil.
+func jsFilter(filter NodeFilter) (js.Func, interface{}) {
+	if filter == nil {
+		return js.Func{}, nil
+	}
+	fn := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		return int(filter(wrapNode(args[0])))
+	})
+	return fn, fn.Value
+}
+
+// TreeWalker navigates the subtree of a root node, like a cursor that
+// can be moved to a node's parent, children and siblings. Nodes that
+// don't match the walker's whatToShow and filter are skipped.
+//
+// Methods that move the walker return the new current node, or nil
+// without moving if there is no such node.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/TreeWalker.
+type TreeWalker struct {
+	js.Value
+	filter js.Func
+}
+
+func (w *TreeWalker) Root() Node         { return wrapNode(w.Get("root")) }
+func (w *TreeWalker) WhatToShow() uint32 { return uint32(w.Get("whatToShow").Int()) }
+func (w *TreeWalker) CurrentNode() Node  { return wrapNode(w.Get("currentNode")) }
+
+// SetCurrentNode moves the walker to n, which doesn't have to be in
+// the walker's subtree or match its filter.
+func (w *TreeWalker) SetCurrentNode(n Node) { w.Set("currentNode", n.Underlying()) }
+
+func (w *TreeWalker) ParentNode() Node      { return wrapNode(w.Call("parentNode")) }
+func (w *TreeWalker) FirstChild() Node      { return wrapNode(w.Call("firstChild")) }
+func (w *TreeWalker) LastChild() Node       { return wrapNode(w.Call("lastChild")) }
+func (w *TreeWalker) PreviousSibling() Node { return wrapNode(w.Call("previousSibling")) }
+func (w *TreeWalker) NextSibling() Node     { return wrapNode(w.Call("nextSibling")) }
+func (w *TreeWalker) PreviousNode() Node    { return wrapNode(w.Call("previousNode")) }
+func (w *TreeWalker) NextNode() Node        { return wrapNode(w.Call("nextNode")) }
+
+// Release frees up resources allocated for the walker's filter. The
+// walker must not be used after calling Release.
+func (w *TreeWalker) Release() {
+	if !w.filter.IsUndefined() {
+		w.filter.Release()
+	}
+}
+
+// NodeIterator iterates over the nodes in the subtree of a root node,
+// in document order. Unlike a TreeWalker, it stays valid when the
+// nodes it has visited are removed from the document.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/NodeIterator.
+type NodeIterator struct {
+	js.Value
+	filter js.Func
+}
+
+func (it *NodeIterator) Root() Node          { return wrapNode(it.Get("root")) }
+func (it *NodeIterator) WhatToShow() uint32  { return uint32(it.Get("whatToShow").Int()) }
+func (it *NodeIterator) ReferenceNode() Node { return wrapNode(it.Get("referenceNode")) }
+func (it *NodeIterator) PointerBeforeReferenceNode() bool {
+	return it.Get("pointerBeforeReferenceNode").Bool()
+}
+
+// NextNode returns the next node and advances the iterator, or
+// returns nil at the end.
+func (it *NodeIterator) NextNode() Node { return wrapNode(it.Call("nextNode")) }
+
+// PreviousNode returns the previous node and moves the iterator
+// backwards, or returns nil at the beginning.
+func (it *NodeIterator) PreviousNode() Node { return wrapNode(it.Call("previousNode")) }
+
+// Release frees up resources allocated for the iterator's filter. The
+// iterator must not be used after calling Release.
+func (it *NodeIterator) Release() {
+	if !it.filter.IsUndefined() {
+		it.filter.Release()
+	}
+}
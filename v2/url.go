@@ -0,0 +1,133 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"net/url"
+	"syscall/js"
+)
+
+// URL is a parsed URL.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/URL.
+type URL struct {
+	js.Value
+	*URLUtils
+}
+
+// NewURL parses s as an absolute URL.
+func NewURL(s string) (*URL, error) {
+	return newURL(s)
+}
+
+// NewURLWithBase parses s as a URL relative to base.
+func NewURLWithBase(s, base string) (*URL, error) {
+	return newURL(s, base)
+}
+
+// NewURLFromURL converts a net/url URL, which must be absolute.
+func NewURLFromURL(u *url.URL) (*URL, error) {
+	return newURL(u.String())
+}
+
+func newURL(args ...interface{}) (u *URL, err error) {
+	defer recoverError(&err)
+	o := js.Global().Get("URL").New(args...)
+	return &URL{Value: o, URLUtils: &URLUtils{Value: o}}, nil
+}
+
+// SearchParams returns the URL's query. Modifying it modifies the URL.
+func (u *URL) SearchParams() *URLSearchParams {
+	return &URLSearchParams{u.Get("searchParams")}
+}
+
+func (u *URL) String() string { return u.Href() }
+
+// URLSearchParams is a list of query parameters. Unlike url.Values,
+// it preserves the order of parameters.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/URLSearchParams.
+type URLSearchParams struct {
+	js.Value
+}
+
+// ParseURLSearchParams parses a query string, with or without a
+// leading question mark. Invalid escape sequences are kept as is.
+func ParseURLSearchParams(query string) *URLSearchParams {
+	return &URLSearchParams{js.Global().Get("URLSearchParams").New(query)}
+}
+
+// NewURLSearchParams returns the parameters in v, sorted by name.
+func NewURLSearchParams(v url.Values) *URLSearchParams {
+	return ParseURLSearchParams(v.Encode())
+}
+
+// Get returns the first value of the parameter name and whether the
+// parameter exists.
+func (p *URLSearchParams) Get(name string) (string, bool) {
+	v := p.Call("get", name)
+	if v.IsNull() {
+		return "", false
+	}
+	return v.String(), true
+}
+
+// GetAll returns all values of the parameter name.
+func (p *URLSearchParams) GetAll(name string) []string {
+	a := p.Call("getAll", name)
+	out := make([]string, a.Length())
+	for i := range out {
+		out[i] = a.Index(i).String()
+	}
+	return out
+}
+
+func (p *URLSearchParams) Has(name string) bool { return p.Call("has", name).Bool() }
+
+// Set sets the parameter name to value, replacing all existing
+// values.
+func (p *URLSearchParams) Set(name, value string) { p.Call("set", name, value) }
+
+// Append adds value to the parameter name.
+func (p *URLSearchParams) Append(name, value string) { p.Call("append", name, value) }
+
+// Delete removes all values of the parameter name.
+func (p *URLSearchParams) Delete(name string) { p.Call("delete", name) }
+
+// Sort sorts the parameters by name, preserving the order of values
+// of parameters with the same name.
+func (p *URLSearchParams) Sort() { p.Call("sort") }
+
+// Size returns the number of name-value pairs.
+func (p *URLSearchParams) Size() int {
+	if size := p.Value.Get("size"); size.Type() == js.TypeNumber {
+		return size.Int()
+	}
+	// Older browsers don't support size.
+	return len(p.Entries())
+}
+
+// Entries returns all name-value pairs, in order.
+func (p *URLSearchParams) Entries() [][2]string {
+	a := js.Global().Get("Array").Call("from", p.Value)
+	out := make([][2]string, a.Length())
+	for i := range out {
+		e := a.Index(i)
+		out[i] = [2]string{e.Index(0).String(), e.Index(1).String()}
+	}
+	return out
+}
+
+// Values converts the parameters to url.Values.
+func (p *URLSearchParams) Values() url.Values {
+	v := url.Values{}
+	for _, e := range p.Entries() {
+		v.Add(e[0], e[1])
+	}
+	return v
+}
+
+// String returns the parameters as a query string, without a leading
+// question mark.
+func (p *URLSearchParams) String() string { return p.Call("toString").String() }
@@ -0,0 +1,391 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+// Values of WebSocket.ReadyState.
+const (
+	WebSocketConnecting = 0
+	WebSocketOpen       = 1
+	WebSocketClosing    = 2
+	WebSocketClosed     = 3
+)
+
+// WebSocket is a WebSocket connection. Besides the event-based API of
+// the browser, which OnOpen, OnMessage, OnError and OnClose simplify,
+// DialWebSocket provides a blocking net.Conn.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/WebSocket.
+type WebSocket struct {
+	*BasicEventTarget
+}
+
+// NewWebSocket connects to url, which must use the ws or wss scheme,
+// requesting one of protocols as the subprotocol. Binary messages are
+// received as ArrayBuffers. NewWebSocket returns an error if url or
+// protocols are invalid; errors that occur while connecting are
+// reported by error and close events.
+func NewWebSocket(url string, protocols ...string) (ws *WebSocket, err error) {
+	defer recoverError(&err)
+	p := make([]interface{}, len(protocols))
+	for i, s := range protocols {
+		p[i] = s
+	}
+	o := js.Global().Get("WebSocket").New(url, p)
+	o.Set("binaryType", "arraybuffer")
+	return &WebSocket{&BasicEventTarget{o}}, nil
+}
+
+func (ws *WebSocket) URL() string { return ws.Get("url").String() }
+
+// ReadyState returns one of WebSocketConnecting, WebSocketOpen,
+// WebSocketClosing and WebSocketClosed.
+func (ws *WebSocket) ReadyState() int { return ws.Get("readyState").Int() }
+
+// BufferedAmount returns the number of bytes that have been queued by
+// Send but not yet transmitted.
+func (ws *WebSocket) BufferedAmount() int { return ws.Get("bufferedAmount").Int() }
+
+// Protocol returns the subprotocol selected by the server.
+func (ws *WebSocket) Protocol() string   { return ws.Get("protocol").String() }
+func (ws *WebSocket) Extensions() string { return ws.Get("extensions").String() }
+
+// BinaryType is "arraybuffer" or "blob", the type of the data of
+// binary messages.
+func (ws *WebSocket) BinaryType() string     { return ws.Get("binaryType").String() }
+func (ws *WebSocket) SetBinaryType(s string) { ws.Set("binaryType", s) }
+
+// Send sends a text message. It returns ErrInvalidState if the
+// connection isn't open yet.
+func (ws *WebSocket) Send(data string) error {
+	return callRecover(ws.Value, "send", data)
+}
+
+// SendBytes sends a binary message. It returns ErrInvalidState if the
+// connection isn't open yet.
+func (ws *WebSocket) SendBytes(data []byte) error {
+	a := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(a, data)
+	return callRecover(ws.Value, "send", a)
+}
+
+// Close closes the connection with a status code, which is either 1000
+// or between 3000 and 4999, and a reason of at most 123 bytes. A code
+// of 0 closes the connection without a status code.
+func (ws *WebSocket) Close(code int, reason string) error {
+	if code == 0 {
+		return callRecover(ws.Value, "close")
+	}
+	return callRecover(ws.Value, "close", code, reason)
+}
+
+// OnOpen adds a listener for the open event, which signals that the
+// connection has been established.
+func (ws *WebSocket) OnOpen(fn func(Event)) js.Func {
+	return ws.AddEventListener("open", false, fn)
+}
+
+// OnMessage adds a listener for received messages. The data of text
+// messages is a string, that of binary messages an ArrayBuffer or a
+// Blob, depending on BinaryType.
+func (ws *WebSocket) OnMessage(fn func(*MessageEvent)) js.Func {
+	return ws.addListener("message", func(ev js.Value) { fn(&MessageEvent{BasicEvent: &BasicEvent{ev}}) })
+}
+
+// OnError adds a listener for the error event. For security reasons,
+// the event has no information about the error; a close event follows.
+func (ws *WebSocket) OnError(fn func(Event)) js.Func {
+	return ws.AddEventListener("error", false, fn)
+}
+
+// OnClose adds a listener for the close event.
+func (ws *WebSocket) OnClose(fn func(*CloseEvent)) js.Func {
+	return ws.addListener("close", func(ev js.Value) { fn(&CloseEvent{BasicEvent: &BasicEvent{ev}}) })
+}
+
+func (ws *WebSocket) addListener(typ string, fn func(js.Value)) js.Func {
+	f := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		fn(args[0])
+		return nil
+	})
+	ws.Call("addEventListener", typ, f)
+	return f
+}
+
+// WebSocketConn adapts a WebSocket to net.Conn. Read returns the
+// contents of received text and binary messages as a stream of bytes;
+// Write sends each call as one binary message. Writes don't block, so
+// write deadlines are ignored.
+type WebSocketConn struct {
+	ws *WebSocket
+
+	readMu sync.Mutex // serializes reads, which consume buf
+
+	mu      sync.Mutex
+	queue   [][]byte // received messages that haven't been read yet
+	buf     []byte   // the unread part of the current message
+	notify  chan struct{}
+	done    chan struct{} // closed when the connection is closed
+	err     error         // the error to return once the queue is empty
+	release func()
+
+	readDeadline connDeadline
+}
+
+// DialWebSocket connects to url like NewWebSocket and waits for the
+// connection to be established or for ctx to be done. Like all
+// blocking functions, it must not be called directly from a JavaScript
+// callback such as an event listener.
+func DialWebSocket(ctx context.Context, url string, protocols ...string) (*WebSocketConn, error) {
+	ws, err := NewWebSocket(url, protocols...)
+	if err != nil {
+		return nil, err
+	}
+	c := &WebSocketConn{
+		ws:           ws,
+		notify:       make(chan struct{}, 1),
+		done:         make(chan struct{}),
+		readDeadline: makeConnDeadline(),
+	}
+	opened := make(chan struct{})
+	var openOnce sync.Once
+	onOpen := ws.addListener("open", func(js.Value) { openOnce.Do(func() { close(opened) }) })
+	onMessage := ws.addListener("message", func(ev js.Value) {
+		data := ev.Get("data")
+		var b []byte
+		if data.Type() == js.TypeString {
+			b = []byte(data.String())
+		} else {
+			if !data.InstanceOf(js.Global().Get("ArrayBuffer")) {
+				// The binary type has been changed to "blob", whose
+				// contents can only be read asynchronously.
+				c.ws.Close(0, "")
+				c.closeWith(errWebSocketBinaryType)
+				return
+			}
+			a := js.Global().Get("Uint8Array").New(data)
+			b = make([]byte, a.Length())
+			js.CopyBytesToGo(b, a)
+		}
+		c.mu.Lock()
+		c.queue = append(c.queue, b)
+		c.mu.Unlock()
+		select {
+		case c.notify <- struct{}{}:
+		default:
+		}
+	})
+	onClose := ws.addListener("close", func(ev js.Value) {
+		ce := &CloseEvent{BasicEvent: &BasicEvent{ev}}
+		var err error = io.EOF
+		if !ce.WasClean() || (ce.Code() != 1000 && ce.Code() != 1005) {
+			err = &WebSocketCloseError{Code: ce.Code(), Reason: ce.Reason()}
+		}
+		c.closeWith(err)
+	})
+	c.release = func() {
+		for _, f := range []struct {
+			typ string
+			fn  js.Func
+		}{{"open", onOpen}, {"message", onMessage}, {"close", onClose}} {
+			ws.Call("removeEventListener", f.typ, f.fn)
+			f.fn.Release()
+		}
+	}
+
+	select {
+	case <-opened:
+		return c, nil
+	case <-c.done:
+		return nil, c.err
+	case <-ctx.Done():
+		c.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// WebSocketCloseError is returned by WebSocketConn.Read when the
+// connection has been closed abnormally or with a status code other
+// than 1000.
+type WebSocketCloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *WebSocketCloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket closed with status %d", e.Code)
+	}
+	return fmt.Sprintf("websocket closed with status %d: %s", e.Code, e.Reason)
+}
+
+var (
+	errWebSocketClosed     = errors.New("dom: use of closed websocket connection")
+	errWebSocketBinaryType = errors.New(`dom: websocket received a Blob; the binary type of a WebSocketConn must stay "arraybuffer"`)
+)
+
+// closeWith marks the connection as closed. Reads return err once all
+// received messages have been read.
+func (c *WebSocketConn) closeWith(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	c.err = err
+	close(c.done)
+	c.release()
+}
+
+// WebSocket returns the underlying WebSocket. Its binary type must not
+// be changed; if it is, the connection is closed once a binary message
+// arrives, and reads fail.
+func (c *WebSocketConn) WebSocket() *WebSocket { return c.ws }
+
+// Read reads the contents of received messages. Concurrent calls are
+// served one after another.
+func (c *WebSocketConn) Read(p []byte) (int, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	for len(c.buf) == 0 {
+		c.mu.Lock()
+		if len(c.queue) > 0 {
+			c.buf = c.queue[0]
+			c.queue = c.queue[1:]
+			c.mu.Unlock()
+			continue
+		}
+		c.mu.Unlock()
+		select {
+		case <-c.notify:
+		case <-c.done:
+			c.mu.Lock()
+			empty := len(c.queue) == 0
+			c.mu.Unlock()
+			if empty {
+				return 0, c.err
+			}
+		case <-c.readDeadline.wait():
+			return 0, timeoutError{}
+		}
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+func (c *WebSocketConn) Write(p []byte) (int, error) {
+	select {
+	case <-c.done:
+		return 0, errWebSocketClosed
+	default:
+	}
+	if err := c.ws.SendBytes(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection with status code 1000.
+func (c *WebSocketConn) Close() error {
+	select {
+	case <-c.done:
+		return errWebSocketClosed
+	default:
+	}
+	err := c.ws.Close(1000, "")
+	c.closeWith(errWebSocketClosed)
+	return err
+}
+
+// LocalAddr returns a placeholder address, as the local address isn't
+// known.
+func (c *WebSocketConn) LocalAddr() net.Addr  { return webSocketAddr("") }
+func (c *WebSocketConn) RemoteAddr() net.Addr { return webSocketAddr(c.ws.URL()) }
+
+func (c *WebSocketConn) SetDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	return nil
+}
+
+func (c *WebSocketConn) SetReadDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	return nil
+}
+
+func (c *WebSocketConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type webSocketAddr string
+
+func (a webSocketAddr) Network() string { return "websocket" }
+func (a webSocketAddr) String() string  { return string(a) }
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// connDeadline is a deadline for net.Conn implementations. The channel
+// returned by wait is closed once the deadline has passed.
+type connDeadline struct {
+	mu     sync.Mutex
+	timer  *time.Timer
+	cancel chan struct{}
+}
+
+func makeConnDeadline() connDeadline {
+	return connDeadline{cancel: make(chan struct{})}
+}
+
+func (d *connDeadline) set(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil && !d.timer.Stop() {
+		// The timer has fired and closed cancel.
+		<-d.cancel
+	}
+	d.timer = nil
+	closed := false
+	select {
+	case <-d.cancel:
+		closed = true
+	default:
+	}
+	if t.IsZero() {
+		if closed {
+			d.cancel = make(chan struct{})
+		}
+		return
+	}
+	if dur := time.Until(t); dur > 0 {
+		if closed {
+			d.cancel = make(chan struct{})
+		}
+		cancel := d.cancel
+		d.timer = time.AfterFunc(dur, func() { close(cancel) })
+		return
+	}
+	if !closed {
+		close(d.cancel)
+	}
+}
+
+func (d *connDeadline) wait() chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cancel
+}
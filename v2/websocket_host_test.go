@@ -0,0 +1,164 @@
+//go:build !js
+// +build !js
+
+package dom
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWebSocketWasm runs TestWebSocketEchoServer under js/wasm against
+// a WebSocket echo server running on the host. It needs Node.js with
+// WebSocket support.
+func TestWebSocketWasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping js/wasm build in short mode")
+	}
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node is not installed")
+	}
+	out, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		t.Skip("cannot find GOROOT:", err)
+	}
+	goroot := strings.TrimSpace(string(out))
+	execDir := ""
+	for _, dir := range []string{"lib/wasm", "misc/wasm"} {
+		if _, err := os.Stat(filepath.Join(goroot, dir, "go_js_wasm_exec")); err == nil {
+			execDir = filepath.Join(goroot, dir)
+			break
+		}
+	}
+	if execDir == "" {
+		t.Skip("go_js_wasm_exec not found")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(serveWebSocketEcho))
+	defer srv.Close()
+
+	cmd := exec.Command("go", "test", "-v", "-run", "^TestWebSocketEchoServer$", ".")
+	cmd.Env = append(os.Environ(),
+		"GOOS=js",
+		"GOARCH=wasm",
+		"PATH="+execDir+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"DOM_WEBSOCKET_ECHO_URL=ws"+strings.TrimPrefix(srv.URL, "http"),
+	)
+	// Node.js 21 and earlier only provide WebSocket behind a flag.
+	if exec.Command("node", "--experimental-websocket", "-e", "").Run() == nil {
+		cmd.Env = append(cmd.Env, "NODE_OPTIONS=--experimental-websocket")
+	}
+	out, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("js/wasm test failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "--- PASS: TestWebSocketEchoServer") {
+		t.Skipf("js/wasm test didn't run:\n%s", out)
+	}
+}
+
+// serveWebSocketEcho is a minimal WebSocket server, as specified by RFC
+// 6455, that sends every frame it receives back to the client.
+func serveWebSocketEcho(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "not a websocket handshake", http.StatusBadRequest)
+		return
+	}
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	sum := sha1.Sum([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	if rw.Flush() != nil {
+		return
+	}
+	for {
+		op, payload, err := readWebSocketFrame(rw.Reader)
+		if err != nil {
+			return
+		}
+		if op&0xf == 0x9 {
+			// Answer pings with pongs.
+			op = op&^0xf | 0xa
+		}
+		if writeWebSocketFrame(rw.Writer, op, payload) != nil || op&0xf == 0x8 {
+			return
+		}
+	}
+}
+
+// readWebSocketFrame reads a frame sent by a client, returning its
+// first byte, which holds the FIN bit and the opcode, and the unmasked
+// payload.
+func readWebSocketFrame(r *bufio.Reader) (byte, []byte, error) {
+	var h [2]byte
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		return 0, nil, err
+	}
+	n := uint64(h[1] & 0x7f)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+	var mask [4]byte
+	if h[1]&0x80 != 0 {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return h[0], payload, nil
+}
+
+// writeWebSocketFrame writes an unmasked frame, as sent by servers.
+func writeWebSocketFrame(w *bufio.Writer, op byte, payload []byte) error {
+	w.WriteByte(op)
+	switch n := len(payload); {
+	case n < 126:
+		w.WriteByte(byte(n))
+	case n <= 0xffff:
+		w.WriteByte(126)
+		var b [2]byte
+		binary.BigEndian.PutUint16(b[:], uint16(n))
+		w.Write(b[:])
+	default:
+		w.WriteByte(127)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(n))
+		w.Write(b[:])
+	}
+	w.Write(payload)
+	return w.Flush()
+}
@@ -0,0 +1,202 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"context"
+	"io"
+	"sync"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+// installFakeWebSocket replaces the WebSocket constructor with an echo
+// server in JavaScript and returns a function that restores it.
+func installFakeWebSocket() func() {
+	global := js.Global()
+	orig := global.Get("WebSocket")
+	global.Set("WebSocket", global.Get("Function").New(`
+		return class extends EventTarget {
+			constructor(url, protocols) {
+				super();
+				this.url = url;
+				this.protocol = protocols[0] || "";
+				this.extensions = "";
+				this.binaryType = "blob";
+				this.bufferedAmount = 0;
+				this.readyState = 0;
+				setTimeout(() => {
+					this.readyState = 1;
+					this.dispatchEvent(new Event("open"));
+				}, 0);
+			}
+			send(data) {
+				if (this.readyState !== 1) throw new DOMException("not open", "InvalidStateError");
+				setTimeout(() => {
+					const ev = new Event("message");
+					if (typeof data === "string") {
+						ev.data = data;
+					} else {
+						const buf = data.buffer.slice(data.byteOffset, data.byteOffset + data.byteLength);
+						ev.data = this.binaryType === "blob" ? new Blob([buf]) : buf;
+					}
+					this.dispatchEvent(ev);
+				}, 0);
+			}
+			close(code, reason) {
+				this.readyState = 3;
+				setTimeout(() => {
+					const ev = new Event("close");
+					Object.assign(ev, {code: code || 1005, reason: reason || "", wasClean: true});
+					this.dispatchEvent(ev);
+				}, 0);
+			}
+		};
+	`).Invoke())
+	return func() { global.Set("WebSocket", orig) }
+}
+
+func TestWebSocketConn(t *testing.T) {
+	if js.Global().Get("EventTarget").IsUndefined() {
+		t.Skip("EventTarget is not available")
+	}
+	defer installFakeWebSocket()()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := DialWebSocket(ctx, "ws://example.com/echo", "echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.WebSocket().Protocol(); got != "echo" {
+		t.Errorf("got protocol %q, want echo", got)
+	}
+	if _, err := c.Write([]byte("hello ")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WebSocket().Send("world"); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 11)
+	if _, err := io.ReadFull(c, buf); err != nil || string(buf) != "hello world" {
+		t.Errorf("got (%q, %v), want hello world", buf, err)
+	}
+
+	c.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	if _, err := c.Read(buf); err == nil || !err.(interface{ Timeout() bool }).Timeout() {
+		t.Errorf("got error %v, want timeout", err)
+	}
+
+	c.Close()
+	if _, err := c.Write(buf); err == nil {
+		t.Error("write after close succeeded")
+	}
+}
+
+func TestWebSocketConnConcurrentReads(t *testing.T) {
+	if js.Global().Get("EventTarget").IsUndefined() {
+		t.Skip("EventTarget is not available")
+	}
+	defer installFakeWebSocket()()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := DialWebSocket(ctx, "ws://example.com/echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	const n = 100
+	for i := 0; i < n; i++ {
+		if _, err := c.Write([]byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := map[byte]bool{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, 1)
+			for {
+				c.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+				if _, err := c.Read(buf); err != nil {
+					return
+				}
+				mu.Lock()
+				seen[buf[0]] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != n {
+		t.Errorf("read %d distinct bytes, want %d", len(seen), n)
+	}
+}
+
+func TestWebSocketConnBlob(t *testing.T) {
+	if js.Global().Get("EventTarget").IsUndefined() || js.Global().Get("Blob").IsUndefined() {
+		t.Skip("EventTarget or Blob is not available")
+	}
+	defer installFakeWebSocket()()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := DialWebSocket(ctx, "ws://example.com/echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.WebSocket().SetBinaryType("blob")
+	if _, err := c.Write([]byte("lost")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Read(make([]byte, 4)); err != errWebSocketBinaryType {
+		t.Errorf("got error %v, want errWebSocketBinaryType", err)
+	}
+}
+
+// TestWebSocketEchoServer talks to the echo server started by
+// TestWebSocketWasm in websocket_host_test.go, which runs this test
+// with the server's URL in DOM_WEBSOCKET_ECHO_URL.
+func TestWebSocketEchoServer(t *testing.T) {
+	url := js.Global().Get("process").Get("env").Get("DOM_WEBSOCKET_ECHO_URL")
+	if url.Type() != js.TypeString {
+		t.Skip("DOM_WEBSOCKET_ECHO_URL is not set")
+	}
+	if js.Global().Get("WebSocket").IsUndefined() {
+		t.Skip("WebSocket is not available")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c, err := DialWebSocket(ctx, url.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.Write([]byte("hello ")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WebSocket().Send("world"); err != nil {
+		t.Fatal(err)
+	}
+	long := make([]byte, 70000)
+	for i := range long {
+		long[i] = byte(i)
+	}
+	if _, err := c.Write(long); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 11)
+	if _, err := io.ReadFull(c, buf); err != nil || string(buf) != "hello world" {
+		t.Errorf("got (%q, %v), want hello world", buf, err)
+	}
+	got := make([]byte, len(long))
+	if _, err := io.ReadFull(c, got); err != nil || string(got) != string(long) {
+		t.Errorf("long message didn't round-trip: %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"context"
+	"syscall/js"
+)
+
+// WorkerOptions are the options for creating workers.
+type WorkerOptions struct {
+	// Type is "classic" or "module". An empty Type means "classic".
+	Type string
+	// Name identifies the worker for debugging. For shared workers,
+	// it also distinguishes workers with the same URL.
+	Name string
+	// Credentials applies to module workers. An empty Credentials
+	// means RequestCredentialsSameOrigin.
+	Credentials RequestCredentials
+}
+
+func (opts WorkerOptions) toJS() map[string]interface{} {
+	o := map[string]interface{}{}
+	if opts.Type != "" {
+		o["type"] = opts.Type
+	}
+	if opts.Name != "" {
+		o["name"] = opts.Name
+	}
+	if opts.Credentials != "" {
+		o["credentials"] = string(opts.Credentials)
+	}
+	return o
+}
+
+// Worker is a dedicated worker, a script running in a separate thread
+// that communicates with its creator by posting messages. A worker
+// can run another Go program, which uses GetDedicatedWorkerGlobalScope
+// to communicate.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/Worker.
+type Worker struct {
+	*BasicEventTarget
+}
+
+// NewWorker starts a worker running the script at url. It returns an
+// error if url is invalid or not allowed; errors while loading the
+// script are reported by error events.
+func NewWorker(url string, opts WorkerOptions) (w *Worker, err error) {
+	defer recoverError(&err)
+	return &Worker{&BasicEventTarget{js.Global().Get("Worker").New(url, opts.toJS())}}, nil
+}
+
+// PostMessage sends a copy of message to the worker, transferring the
+// objects in transfer. message is converted like for
+// Window.PostMessage.
+func (w *Worker) PostMessage(message interface{}, transfer []interface{}) error {
+	return postMessage(w.Value, message, transfer)
+}
+
+// Terminate stops the worker immediately.
+func (w *Worker) Terminate() { w.Call("terminate") }
+
+// OnMessage adds a listener for messages from the worker.
+func (w *Worker) OnMessage(fn func(*MessageEvent)) js.Func {
+	return addMessageListener(w.BasicEventTarget, fn)
+}
+
+// OnError adds a listener for errors in the worker. Uncaught
+// exceptions are reported as *ErrorEvent.
+func (w *Worker) OnError(fn func(Event)) js.Func {
+	return w.AddEventListener("error", false, fn)
+}
+
+// Messages returns a channel of the messages from the worker, which is
+// closed when ctx is done.
+func (w *Worker) Messages(ctx context.Context) <-chan *MessageEvent {
+	return receiveMessages(ctx, w.Value)
+}
+
+// DedicatedWorkerGlobalScope is the global object of a dedicated
+// worker. Code running in a worker uses it to communicate with the
+// worker's creator.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/DedicatedWorkerGlobalScope.
+type DedicatedWorkerGlobalScope struct {
+	*BasicEventTarget
+}
+
+// GetDedicatedWorkerGlobalScope returns the global object if the
+// program runs in a dedicated worker, and nil otherwise.
+func GetDedicatedWorkerGlobalScope() *DedicatedWorkerGlobalScope {
+	global := js.Global()
+	c := global.Get("DedicatedWorkerGlobalScope")
+	if c.Type() != js.TypeFunction || !global.InstanceOf(c) {
+		return nil
+	}
+	return &DedicatedWorkerGlobalScope{&BasicEventTarget{global}}
+}
+
+// Name returns the name passed in WorkerOptions.
+func (s *DedicatedWorkerGlobalScope) Name() string { return s.Get("name").String() }
+
+// PostMessage sends a copy of message to the worker's creator,
+// transferring the objects in transfer. message is converted like for
+// Window.PostMessage.
+func (s *DedicatedWorkerGlobalScope) PostMessage(message interface{}, transfer []interface{}) error {
+	return postMessage(s.Value, message, transfer)
+}
+
+// Close stops the worker once the current task has finished.
+func (s *DedicatedWorkerGlobalScope) Close() { s.Call("close") }
+
+// OnMessage adds a listener for messages from the worker's creator.
+func (s *DedicatedWorkerGlobalScope) OnMessage(fn func(*MessageEvent)) js.Func {
+	return addMessageListener(s.BasicEventTarget, fn)
+}
+
+// Messages returns a channel of the messages from the worker's
+// creator, which is closed when ctx is done.
+func (s *DedicatedWorkerGlobalScope) Messages(ctx context.Context) <-chan *MessageEvent {
+	return receiveMessages(ctx, s.Value)
+}
+
+// SharedWorker is a worker that is shared by all windows and workers
+// of the same origin that create it with the same URL and name. Each
+// of them communicates with it through its own MessagePort.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/SharedWorker.
+type SharedWorker struct {
+	*BasicEventTarget
+}
+
+// NewSharedWorker connects to the shared worker running the script at
+// url, starting it if necessary.
+func NewSharedWorker(url string, opts WorkerOptions) (w *SharedWorker, err error) {
+	defer recoverError(&err)
+	return &SharedWorker{&BasicEventTarget{js.Global().Get("SharedWorker").New(url, opts.toJS())}}, nil
+}
+
+// Port returns the port for communicating with the worker.
+func (w *SharedWorker) Port() *MessagePort {
+	return &MessagePort{&BasicEventTarget{w.Get("port")}}
+}
+
+// OnError adds a listener for errors in the worker.
+func (w *SharedWorker) OnError(fn func(Event)) js.Func {
+	return w.AddEventListener("error", false, fn)
+}
+
+// BroadcastChannel sends messages to all windows and workers of the
+// same origin that have joined the channel with the same name.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/BroadcastChannel.
+type BroadcastChannel struct {
+	*BasicEventTarget
+}
+
+// NewBroadcastChannel joins the channel called name.
+func NewBroadcastChannel(name string) *BroadcastChannel {
+	return &BroadcastChannel{&BasicEventTarget{js.Global().Get("BroadcastChannel").New(name)}}
+}
+
+func (c *BroadcastChannel) Name() string { return c.Get("name").String() }
+
+// PostMessage sends a copy of message to all other members of the
+// channel. message is converted like for Window.PostMessage.
+func (c *BroadcastChannel) PostMessage(message interface{}) error {
+	return postMessage(c.Value, message, nil)
+}
+
+// Close leaves the channel.
+func (c *BroadcastChannel) Close() { c.Call("close") }
+
+// OnMessage adds a listener for messages from other members of the
+// channel.
+func (c *BroadcastChannel) OnMessage(fn func(*MessageEvent)) js.Func {
+	return addMessageListener(c.BasicEventTarget, fn)
+}
+
+// Messages returns a channel of the messages from other members of the
+// channel, which is closed when ctx is done.
+func (c *BroadcastChannel) Messages(ctx context.Context) <-chan *MessageEvent {
+	return receiveMessages(ctx, c.Value)
+}
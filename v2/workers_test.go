@@ -0,0 +1,234 @@
+//go:build js
+// +build js
+
+package dom
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+func TestBroadcastChannel(t *testing.T) {
+	if js.Global().Get("BroadcastChannel").IsUndefined() {
+		t.Skip("BroadcastChannel is not available")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	a := NewBroadcastChannel("dom-test")
+	defer a.Close()
+	b := NewBroadcastChannel("dom-test")
+	defer b.Close()
+	if got := b.Name(); got != "dom-test" {
+		t.Errorf("got name %q, want %q", got, "dom-test")
+	}
+	msgs := b.Messages(ctx)
+
+	if err := a.PostMessage(map[string]int{"n": 42}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-msgs:
+		var m map[string]int
+		if err := ev.DecodeData(&m); err != nil || m["n"] != 42 {
+			t.Errorf("got (%v, %v), want map[n:42]", m, err)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	if s := GetDedicatedWorkerGlobalScope(); s != nil {
+		t.Error("got a DedicatedWorkerGlobalScope outside of a worker")
+	}
+}
+
+// fakeWorker is a Worker constructor whose workers echo messages back,
+// except for "crash", which makes them report an error, like an
+// uncaught exception in a real worker does.
+const fakeWorker = `class extends EventTarget {
+	constructor(url, options) {
+		super();
+		if (url === "") throw new DOMException("invalid URL", "SyntaxError");
+		this.url = url;
+		this.options = options;
+		this.terminated = false;
+	}
+	postMessage(data, transfer) {
+		this.transfer = transfer;
+		setTimeout(() => {
+			if (this.terminated) return;
+			if (data === "crash") {
+				this.dispatchEvent(Object.assign(new Event("error"), {constructor: ErrorEvent, message: "Uncaught Error: crash"}));
+			} else {
+				this.dispatchEvent(new MessageEvent("message", {data}));
+			}
+		}, 0);
+	}
+	terminate() { this.terminated = true; }
+}`
+
+func TestWorker(t *testing.T) {
+	defer installGlobal("Worker", fakeWorker)()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := NewWorker("", WorkerOptions{}); err == nil {
+		t.Error("NewWorker succeeded with an invalid URL")
+	}
+	w, err := NewWorker("plain.js", WorkerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := js.Global().Get("Object").Call("keys", w.Get("options")).Length(); n != 0 {
+		t.Errorf("got %d options, want none", n)
+	}
+	w, err = NewWorker("worker.js", WorkerOptions{Type: "module", Name: "w", Credentials: RequestCredentialsInclude})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts := w.Get("options"); opts.Get("type").String() != "module" || opts.Get("name").String() != "w" || opts.Get("credentials").String() != "include" {
+		t.Errorf("got options %v", js.Global().Get("JSON").Call("stringify", opts))
+	}
+
+	msgs := w.Messages(ctx)
+	received := make(chan string, 1)
+	defer w.OnMessage(func(ev *MessageEvent) {
+		var s string
+		ev.DecodeData(&s)
+		received <- s
+	}).Release()
+	errs := make(chan Event, 1)
+	defer w.OnError(func(ev Event) { errs <- ev }).Release()
+
+	type point struct{ X, Y int }
+	c := NewMessageChannel()
+	defer c.Port2().Close()
+	if err := w.PostMessage(point{1, 2}, []interface{}{c.Port1()}); err != nil {
+		t.Fatal(err)
+	}
+	if tr := w.Get("transfer"); tr.Length() != 1 || !tr.Index(0).Equal(c.Port1().Value) {
+		t.Errorf("got transfer list %v, want the port", tr)
+	}
+	select {
+	case ev := <-msgs:
+		var p point
+		if err := ev.DecodeData(&p); err != nil || p != (point{1, 2}) {
+			t.Errorf("got (%v, %v), want {1 2}", p, err)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+	<-received
+
+	if err := w.PostMessage("hello", nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case s := <-received:
+		if s != "hello" {
+			t.Errorf("OnMessage got %q, want hello", s)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+	<-msgs
+
+	if err := w.PostMessage("crash", nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-errs:
+		if ee, ok := ev.(*ErrorEvent); !ok || ee.Message() != "Uncaught Error: crash" {
+			t.Errorf("got %T, want *ErrorEvent for the crash", ev)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	w.Terminate()
+	if !w.Get("terminated").Bool() {
+		t.Error("Terminate didn't terminate the worker")
+	}
+}
+
+func TestSharedWorker(t *testing.T) {
+	if js.Global().Get("MessageChannel").IsUndefined() {
+		t.Skip("MessageChannel is not available")
+	}
+	// Each shared worker echoes the messages sent to its port.
+	defer installGlobal("SharedWorker", `class extends EventTarget {
+		constructor(url, options) {
+			super();
+			this.url = url;
+			this.options = options;
+			const c = new MessageChannel();
+			this.port = c.port1;
+			c.port2.onmessage = (e) => c.port2.postMessage(e.data);
+		}
+	}`)()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	w, err := NewSharedWorker("shared.js", WorkerOptions{Name: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Port().Close()
+	if name := w.Get("options").Get("name").String(); name != "s" {
+		t.Errorf("got name %q, want s", name)
+	}
+	msgs := w.Port().Messages(ctx)
+	if err := w.Port().PostMessage(map[string]interface{}{"n": 42}, nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-msgs:
+		var m map[string]int
+		if err := ev.DecodeData(&m); err != nil || m["n"] != 42 {
+			t.Errorf("got (%v, %v), want map[n:42]", m, err)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+}
+
+func TestDedicatedWorkerGlobalScope(t *testing.T) {
+	restore := installGlobal("DedicatedWorkerGlobalScope", `class {
+		static [Symbol.hasInstance](o) { return o === globalThis; }
+	}`)
+	s := GetDedicatedWorkerGlobalScope()
+	restore()
+	if s == nil || !s.Value.Equal(js.Global()) {
+		t.Fatal("didn't get the global object as a DedicatedWorkerGlobalScope")
+	}
+
+	// The scope's creator echoes the messages it receives.
+	scope := js.Global().Get("Function").New(`return new (class extends EventTarget {
+		postMessage(data, transfer) {
+			this.transfer = transfer;
+			setTimeout(() => this.dispatchEvent(new MessageEvent("message", {data})), 0);
+		}
+	})();`).Invoke()
+	s = &DedicatedWorkerGlobalScope{&BasicEventTarget{scope}}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	msgs := s.Messages(ctx)
+	if err := s.PostMessage([]byte("hi"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if !scope.Get("transfer").IsUndefined() {
+		t.Errorf("got transfer list %v, want none", scope.Get("transfer"))
+	}
+	select {
+	case ev := <-msgs:
+		var b []byte
+		if err := ev.DecodeData(&b); err != nil || string(b) != "hi" {
+			t.Errorf("got (%q, %v), want hi", b, err)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+}